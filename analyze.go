@@ -0,0 +1,670 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DiagnosticKind describes the kind of issue reported by Router.Analyze.
+type DiagnosticKind uint8
+
+const (
+	// DiagnosticShadowed denotes the route can never be selected.
+	DiagnosticShadowed DiagnosticKind = iota + 1
+
+	// DiagnosticAmbiguous denotes the routes match some of the same paths, which are handled by the route taking the
+	// priority (static > constrained param > param > wildcard at the first differing position).
+	DiagnosticAmbiguous
+
+	// DiagnosticTrailingSlash denotes the routes differ only by the trailing slash. Requests for either of the paths
+	// are matched exactly, thus the trailing slash match never applies between them.
+	DiagnosticTrailingSlash
+
+	// DiagnosticCaseCollision denotes the path correction picks one of the routes for the requests matching neither of
+	// them exactly, either because the routes differ only by the case of their static parts or because the path
+	// correction picks a different route than the exact match.
+	DiagnosticCaseCollision
+)
+
+// String returns the name of the DiagnosticKind.
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticShadowed:
+		return "shadowed"
+	case DiagnosticAmbiguous:
+		return "ambiguous"
+	case DiagnosticTrailingSlash:
+		return "trailing slash"
+	case DiagnosticCaseCollision:
+		return "case collision"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic describes an issue of a route, usually in relation to another route. See Router.Analyze.
+type Diagnostic struct {
+	Kind    DiagnosticKind
+	Method  string // Empty when the routes are registered with All.
+	Host    string // Host pattern the routes are registered for.
+	Path    string
+	Other   string // Path of the other route. For the shadowed routes, the host pattern taking the priority.
+	Example string // Request path (or host for the shadowed routes) demonstrating the issue.
+	Winner  string // Path of the route which handles the Example, if any.
+	Message string
+}
+
+// String returns the kind and the message of the Diagnostic.
+func (d Diagnostic) String() string {
+	return d.Kind.String() + ": " + d.Message
+}
+
+// Analyze reports the routes which don't conflict enough to fail Router.Serve, but may not route the requests as
+// intended. e.g.:
+//
+//	router.GET("/v:version/jobs", ListJobs)
+//	router.GET("/v1/jobs", ListJobsV1)
+//
+//	for _, d := range router.Analyze() {
+//		log.Println(d) // ambiguous: GET /v:version/jobs and GET /v1/jobs both match /v1/jobs, which is handled by /v1/jobs
+//	}
+//
+// It reports the routes of host patterns shadowed by the host patterns taking the priority, the routes which never
+// handle the paths they match since other routes take the priority, the pairs of routes matching the same paths
+// (including the routes registered with All overlapping the routes of a method), the pairs of routes differing only by
+// the trailing slash and the pairs of routes the path correction picks between.
+//
+// Overlaps are confirmed by routing a sample path through the routes. Since constraints are evaluated with sample
+// values, the overlaps depending on particular values of the constrained params may not be reported. Likewise, a
+// route is reported as shadowed when none of its sample paths are handled by the route.
+// The sample paths are routed through the Server generated by Router.Serve, thus Analyze panics on the same
+// conflicts as Router.Serve.
+func (r *Router) Analyze() []Diagnostic {
+	svr := r.Serve()
+	a := &analyzer{
+		constraints: r.config.constraints,
+		trees:       map[string]*radixMux{},
+		servers:     map[string]*Server{"": svr},
+	}
+
+	for _, h := range svr.hosts {
+		a.servers[h.pattern] = h.svr
+	}
+
+	var (
+		hosts   []string
+		byHosts = map[string][]analyzedRoute{}
+	)
+
+	for i, log := range *r.logs {
+		if _, ok := byHosts[log.host]; !ok {
+			hosts = append(hosts, log.host)
+		}
+
+		for _, variant := range expandOptionalSegments(log.path) {
+			byHosts[log.host] = append(byHosts[log.host], analyzedRoute{
+				method: log.method,
+				path:   log.path,
+				elems:  patternElems(variant),
+				seq:    i,
+			})
+		}
+	}
+
+	a.analyzeHosts(hosts, byHosts)
+	for _, host := range hosts {
+		shadowed := a.analyzeShadowed(host, byHosts[host])
+		a.analyzeRoutes(host, byHosts[host], shadowed)
+	}
+
+	return a.diagnostics
+}
+
+type analyzer struct {
+	constraints map[string]ConstraintFunc
+	trees       map[string]*radixMux // Route path -> tree holding only the route. Nil for the invalid paths.
+	servers     map[string]*Server   // Host pattern -> Server of the routes registered for the host pattern.
+	diagnostics []Diagnostic
+}
+
+// analyzedRoute is a route variant for each combination of the optional segments of a route.
+type analyzedRoute struct {
+	method string
+	path   string
+	elems  []patternElem // Elements of the variant.
+	seq    int           // Position of the route in the registration order.
+}
+
+// analyzeHosts reports the routes of the host patterns which are never matched, because a host pattern taking the
+// priority matches all the hosts the host pattern matches.
+func (a *analyzer) analyzeHosts(hosts []string, byHosts map[string][]analyzedRoute) {
+	var patterns []*hostRoutes
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+
+		var h *hostRoutes
+		if recoverString(func() { h = newHostRoutes(host, nil) }) != "" {
+			continue
+		}
+		patterns = append(patterns, h)
+	}
+
+	// Static host patterns take priority over host patterns with params. See Router.Serve.
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].isStatic() && !patterns[j].isStatic()
+	})
+
+	for j, h := range patterns {
+		for _, prior := range patterns[:j] {
+			if !coversHost(prior.labels, h.labels) {
+				continue
+			}
+
+			seen := map[int]bool{}
+			for _, route := range byHosts[h.pattern] {
+				if seen[route.seq] {
+					continue
+				}
+				seen[route.seq] = true
+
+				a.report(Diagnostic{
+					Kind:    DiagnosticShadowed,
+					Method:  route.method,
+					Host:    h.pattern,
+					Path:    route.path,
+					Other:   prior.pattern,
+					Example: sampleHost(h.labels),
+					Message: fmt.Sprintf("%s for host %s is never matched, since host %s takes the priority", routeLabel(route.method, route.path), h.pattern, prior.pattern),
+				})
+			}
+			break
+		}
+	}
+}
+
+// coversHost reports whether the host pattern of the labels matches all the hosts the other host pattern matches.
+func coversHost(labels []string, other []string) bool {
+	if len(labels) != len(other) {
+		return false
+	}
+
+	for i, l := range labels {
+		if l[0] != ':' && (other[i][0] == ':' || !strings.EqualFold(l, other[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func sampleHost(labels []string) string {
+	sample := make([]string, len(labels))
+	for i, l := range labels {
+		if l[0] == ':' {
+			l = "x"
+		}
+		sample[i] = l
+	}
+	return strings.Join(sample, ".")
+}
+
+// shadowSamples are the chars filling the params and the wildcards of the sample paths of a route when looking for the
+// paths the route handles. It has more samples than samples, since a single sample handled by the route suffices.
+var shadowSamples = []byte{'1', 'a', 'A', '-', '_', '~'}
+
+// analyzeShadowed reports the routes which don't handle any of the sample paths they match, since other routes take
+// the priority for all the variants of the routes. Returns the positions of the reported routes mapped to the paths of
+// the routes taking the priority.
+func (a *analyzer) analyzeShadowed(host string, routes []analyzedRoute) map[int]string {
+	type verdict struct {
+		route   analyzedRoute
+		handled bool   // Denotes a sample path is handled by the route.
+		example string // Sample path matched by the route, which is handled by the winner.
+		winner  string
+	}
+
+	var order []*verdict
+	verdicts := map[int]*verdict{}
+	for _, route := range routes {
+		v, ok := verdicts[route.seq]
+		if !ok {
+			v = &verdict{route: route}
+			verdicts[route.seq] = v
+			order = append(order, v)
+		}
+
+		if v.handled {
+			continue
+		}
+
+		methods := []string{route.method}
+		if route.method == "" {
+			methods = builtInMethods
+		}
+
+	samples:
+		for _, sample := range shadowSamples {
+			for _, example := range samplePaths(route.elems, sample) {
+				if !a.matches(route.path, example, false) {
+					continue
+				}
+
+				for _, method := range methods {
+					winner, ok := winner(a.servers[host], method, example, false)
+					if !ok {
+						continue
+					}
+
+					if winner == route.path {
+						v.handled = true
+						break samples
+					}
+
+					if v.winner == "" {
+						v.example, v.winner = example, winner
+					}
+				}
+			}
+		}
+	}
+
+	shadowed := map[int]string{}
+	for _, v := range order {
+		if v.handled || v.winner == "" {
+			continue
+		}
+
+		a.report(Diagnostic{
+			Kind:    DiagnosticShadowed,
+			Method:  v.route.method,
+			Host:    host,
+			Path:    v.route.path,
+			Other:   v.winner,
+			Example: v.example,
+			Winner:  v.winner,
+			Message: fmt.Sprintf("%s is never matched, since %s takes the priority for the paths it matches, e.g.: %s", routeLabel(v.route.method, v.route.path), v.winner, v.example),
+		})
+		shadowed[v.route.seq] = v.winner
+	}
+
+	return shadowed
+}
+
+// samplePaths returns the paths matched by the pattern with the params filled with the sample char, and the wildcard
+// filled with nothing, the sample char and two segments of the sample char.
+func samplePaths(elems []patternElem, sample byte) []string {
+	fills := []string{""}
+	for _, e := range elems {
+		if e.kind == elemAnyStar {
+			fills = []string{"", string(sample), string(sample) + "/" + string(sample)}
+			break
+		}
+	}
+
+	paths := make([]string, 0, len(fills))
+	for _, fill := range fills {
+		var b strings.Builder
+		for _, e := range elems {
+			switch e.kind {
+			case elemLiteral:
+				b.WriteByte(e.c)
+			case elemSegmentChar:
+				b.WriteByte(sample)
+			case elemAnyStar:
+				b.WriteString(fill)
+			}
+		}
+		paths = append(paths, b.String())
+	}
+	return paths
+}
+
+// analyzeRoutes reports the issues between each pair of the routes registered for the host pattern.
+// shadowed maps the positions of the shadowed routes to the paths of the routes taking the priority. The overlaps of
+// the shadowed routes with those routes are not reported as ambiguous, since they're reported as shadowed.
+func (a *analyzer) analyzeRoutes(host string, routes []analyzedRoute, shadowed map[int]string) {
+	type pair struct{ a, b int }
+	reported := map[pair]bool{}
+
+	for j := range routes {
+		for i := range routes[:j] {
+			ra, rb := routes[i], routes[j]
+			if ra.seq == rb.seq || ra.path == rb.path || ra.method != rb.method && ra.method != "" && rb.method != "" {
+				// Routes of the same path are either merged (see Core.When) or fail Router.Serve.
+				continue
+			}
+
+			if reported[pair{ra.seq, rb.seq}] {
+				continue
+			}
+
+			covered := shadowed[ra.seq] == rb.path || shadowed[rb.seq] == ra.path
+			for _, d := range a.analyzePair(a.servers[host], ra, rb, covered) {
+				d.Host = host
+				a.report(d)
+				reported[pair{ra.seq, rb.seq}] = true
+			}
+		}
+	}
+}
+
+// analyzePair returns the issues between the routes. The overlap is not reported as ambiguous if covered, since one of
+// the routes is reported as shadowed by the other. The overlap under the path correction is checked separately, and
+// reported when the path correction picks a different route than the exact match.
+func (a *analyzer) analyzePair(svr *Server, ra analyzedRoute, rb analyzedRoute, covered bool) (diagnostics []Diagnostic) {
+	method := ra.method
+	if method == "" {
+		method = rb.method
+	}
+
+	d := Diagnostic{
+		Method: method,
+		Path:   ra.path,
+		Other:  rb.path,
+	}
+	labels := routeLabel(ra.method, ra.path) + " and " + routeLabel(rb.method, rb.path)
+
+	if twins(ra.elems, rb.elems) {
+		d.Kind = DiagnosticTrailingSlash
+		d.Message = fmt.Sprintf("%s differ only by the trailing slash, thus the trailing slash match never applies to them", labels)
+		return []Diagnostic{d}
+	}
+
+	var exact, exactWinner string
+	if example, ok := a.overlap(ra, rb, false); ok {
+		if winner, ok := winner(svr, method, example, false); ok {
+			exact, exactWinner = example, winner
+			if !covered {
+				d := d
+				d.Kind, d.Example, d.Winner = DiagnosticAmbiguous, example, winner
+				d.Message = fmt.Sprintf("%s both match %s, which is handled by %s", labels, example, winner)
+				diagnostics = append(diagnostics, d)
+			}
+		}
+	}
+
+	if example, ok := a.overlap(ra, rb, true); ok {
+		if winner, ok := winner(svr, method, example, true); ok && winner != exactWinner {
+			d.Kind, d.Example, d.Winner = DiagnosticCaseCollision, example, winner
+			if exactWinner == "" {
+				d.Message = fmt.Sprintf("%s differ only by case, thus the path correction of %s picks %s", labels, example, winner)
+			} else {
+				d.Message = fmt.Sprintf("%s both match %s, which is handled by %s, but the path correction of %s picks %s", labels, exact, exactWinner, example, winner)
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	return diagnostics
+}
+
+func (a *analyzer) report(d Diagnostic) {
+	a.diagnostics = append(a.diagnostics, d)
+}
+
+// samples are the chars filling the params and the wildcards of the sample paths. Multiple samples are tried to satisfy
+// the common constraints.
+var samples = []byte{'1', 'a', 'A'}
+
+// overlap returns a sample path matched by both the routes, comparing the static parts case-insensitively if fold.
+// When fold, the sample path matches neither of the routes exactly.
+func (a *analyzer) overlap(ra analyzedRoute, rb analyzedRoute, fold bool) (string, bool) {
+	for _, sample := range samples {
+		example, ok := intersect(ra.elems, rb.elems, fold, sample)
+		if !ok {
+			// The intersection doesn't depend on the sample.
+			return "", false
+		}
+
+		if a.matches(ra.path, example, fold) && a.matches(rb.path, example, fold) {
+			return example, true
+		}
+	}
+	return "", false
+}
+
+// matches reports whether the route matches the path, case-insensitively if fold.
+func (a *analyzer) matches(routePath string, path string, fold bool) bool {
+	mux, ok := a.trees[routePath]
+	if !ok {
+		mux = a.tree(routePath)
+		a.trees[routePath] = mux
+	}
+
+	if mux == nil {
+		return false
+	}
+
+	if fold {
+		h, _, _, _, _ := mux.findCaseInsensitive(path, false)
+		return h != nil
+	}
+
+	h, _, _, _ := mux.find(path)
+	return h != nil
+}
+
+// winner returns the path of the route handling the path in the Server.
+// When fold, the path must not match a route exactly, since the path correction applies otherwise.
+func winner(svr *Server, method string, path string, fold bool) (string, bool) {
+	if method == "" {
+		// Routes registered with All are registered for all the built-in methods.
+		method = http.MethodGet
+	}
+
+	mux := svr.mux(method)
+	if mux == nil {
+		return "", false
+	}
+
+	h, ps, template, _ := mux.find(path)
+	if ps != nil {
+		mux.release(ps)
+	}
+
+	if fold {
+		if h != nil {
+			return "", false
+		}
+		h, _, template, _, _ = mux.findCaseInsensitive(path, false)
+	}
+
+	return template, h != nil
+}
+
+// tree returns a radix tree holding only the route. Returns <nil> if the route path is invalid.
+func (a *analyzer) tree(path string) (mux *radixMux) {
+	if recoverString(func() {
+		mux = newRadixMux(a.constraints)
+		mux.add(path, isStatic(path), fakeAnalyzerHandler, nil)
+	}) != "" {
+		return nil
+	}
+	return
+}
+
+func fakeAnalyzerHandler(w http.ResponseWriter, r *http.Request, route Route) error {
+	return nil
+}
+
+func routeLabel(method string, path string) string {
+	if method == "" {
+		method = "ALL"
+	}
+	return method + " " + path
+}
+
+type patternElemKind uint8
+
+const (
+	elemLiteral     patternElemKind = iota // The char.
+	elemSegmentChar                        // Any char other than '/'.
+	elemSegmentStar                        // Zero or more chars other than '/'.
+	elemAnyStar                            // Zero or more chars.
+)
+
+type patternElem struct {
+	kind patternElemKind
+	c    byte
+	expr string // Constraint of the param. Only used to compare the patterns.
+}
+
+func (e patternElem) isStar() bool {
+	return e.kind == elemSegmentStar || e.kind == elemAnyStar
+}
+
+// patternElems compiles the route path into a sequence of elements matching the same paths, ignoring the constraints.
+// A param matches one or more chars other than '/' and a wildcard matches zero or more chars.
+func patternElems(path string) []patternElem {
+	var elems []patternElem
+
+	r := newRouteScanner(path)
+	for seg := r.next(); seg != ""; seg = r.next() {
+		switch seg[0] {
+		case ':':
+			_, expr := splitParam(seg)
+			elems = append(elems, patternElem{kind: elemSegmentChar, expr: expr}, patternElem{kind: elemSegmentStar})
+		case '*':
+			elems = append(elems, patternElem{kind: elemAnyStar})
+		default:
+			for i := 0; i < len(seg); i++ {
+				elems = append(elems, patternElem{kind: elemLiteral, c: seg[i]})
+			}
+		}
+	}
+
+	return elems
+}
+
+// twins reports whether the patterns differ only by a trailing slash.
+func twins(a []patternElem, b []patternElem) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	if len(b) != len(a)+1 || b[len(b)-1] != (patternElem{kind: elemLiteral, c: '/'}) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == 0 || a[len(a)-1].kind != elemAnyStar
+}
+
+// intersect searches a path matched by both the patterns, comparing the literals case-insensitively if fold.
+// The params and the wildcards are filled with the sample char. When fold, the letters matched by the literals of
+// both the patterns are swapped case where possible.
+func intersect(a []patternElem, b []patternElem, fold bool, sample byte) (string, bool) {
+	type state struct{ i, j int }
+	type step struct {
+		prev state
+		c    byte
+		eps  bool // Denotes the step doesn't consume a char.
+	}
+
+	start, end := state{0, 0}, state{len(a), len(b)}
+	steps := map[state]step{start: {}}
+	queue := []state{start}
+
+	visit := func(from state, to state, c byte, eps bool) {
+		if _, ok := steps[to]; !ok {
+			steps[to] = step{from, c, eps}
+			queue = append(queue, to)
+		}
+	}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		if s == end {
+			break
+		}
+
+		// Stars can be skipped without consuming a char.
+		if s.i < len(a) && a[s.i].isStar() {
+			visit(s, state{s.i + 1, s.j}, 0, true)
+		}
+		if s.j < len(b) && b[s.j].isStar() {
+			visit(s, state{s.i, s.j + 1}, 0, true)
+		}
+
+		if s.i == len(a) || s.j == len(b) {
+			continue
+		}
+
+		ea, eb := a[s.i], b[s.j]
+		c, ok := intersectChar(ea, eb, fold, sample, s.i%2 == 1)
+		if !ok {
+			continue
+		}
+
+		next := s
+		if !ea.isStar() {
+			next.i++
+		}
+		if !eb.isStar() {
+			next.j++
+		}
+		visit(s, next, c, false)
+	}
+
+	if _, ok := steps[end]; !ok {
+		return "", false
+	}
+
+	var path []byte
+	for s := end; s != start; s = steps[s].prev {
+		if st := steps[s]; !st.eps {
+			path = append(path, st.c)
+		}
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return string(path), true
+}
+
+// intersectChar returns a char matched by both the elements. When fold and the literals differ by case, the char of
+// the first element is returned if preferA, otherwise the char of the second element. Alternating it along the path
+// yields a path matching neither of the patterns exactly, even if all their letters differ by case.
+func intersectChar(a patternElem, b patternElem, fold bool, sample byte, preferA bool) (byte, bool) {
+	if a.kind != elemLiteral {
+		a, b = b, a
+	}
+
+	if a.kind != elemLiteral {
+		// Both the elements match any char other than '/'.
+		return sample, true
+	}
+
+	c := a.c
+	switch b.kind {
+	case elemLiteral:
+		if c != b.c && !(fold && strings.EqualFold(string(c), string(b.c))) {
+			return 0, false
+		}
+		if fold && c == b.c {
+			c, _ = swapCase(c)
+		} else if !preferA {
+			c = b.c
+		}
+		return c, true
+	case elemSegmentChar, elemSegmentStar:
+		if c == '/' {
+			return 0, false
+		}
+	}
+
+	if fold {
+		c, _ = swapCase(c)
+	}
+	return c, true
+}
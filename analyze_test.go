@@ -0,0 +1,170 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRouter_Analyze(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/v:version/jobs", fakeHandler())
+	r.GET("/v1/jobs", fakeHandler())
+	r.GET("/files/:name", fakeHandler())
+	r.All("/files/*path", fakeHandler())
+	r.GET("/users", fakeHandler())
+	r.GET("/users/", fakeHandler())
+	r.GET("/About", fakeHandler())
+	r.GET("/about", fakeHandler())
+	r.GET("/archive/:year?", fakeHandler())
+	r.GET("/archive/latest", fakeHandler())
+	r.Host(":tenant.example.com", func(g *Group) {
+		g.GET("/dashboard", fakeHandler())
+	})
+	r.Host(":sub.example.com", func(g *Group) {
+		g.POST("/login", fakeHandler())
+	})
+
+	expected := []Diagnostic{
+		{
+			Kind:    DiagnosticShadowed,
+			Method:  http.MethodPost,
+			Host:    ":sub.example.com",
+			Path:    "/login",
+			Other:   ":tenant.example.com",
+			Example: "x.example.com",
+			Message: "POST /login for host :sub.example.com is never matched, since host :tenant.example.com takes the priority",
+		},
+		{
+			Kind:    DiagnosticAmbiguous,
+			Method:  http.MethodGet,
+			Path:    "/v:version/jobs",
+			Other:   "/v1/jobs",
+			Example: "/v1/jobs",
+			Winner:  "/v1/jobs",
+			Message: "GET /v:version/jobs and GET /v1/jobs both match /v1/jobs, which is handled by /v1/jobs",
+		},
+		{
+			Kind:    DiagnosticAmbiguous,
+			Method:  http.MethodGet,
+			Path:    "/files/:name",
+			Other:   "/files/*path",
+			Example: "/files/1",
+			Winner:  "/files/:name",
+			Message: "GET /files/:name and ALL /files/*path both match /files/1, which is handled by /files/:name",
+		},
+		{
+			Kind:    DiagnosticTrailingSlash,
+			Method:  http.MethodGet,
+			Path:    "/users",
+			Other:   "/users/",
+			Message: "GET /users and GET /users/ differ only by the trailing slash, thus the trailing slash match never applies to them",
+		},
+		{
+			Kind:    DiagnosticCaseCollision,
+			Method:  http.MethodGet,
+			Path:    "/About",
+			Other:   "/about",
+			Example: "/ABOUT",
+			Winner:  "/About",
+			Message: "GET /About and GET /about differ only by case, thus the path correction of /ABOUT picks /About",
+		},
+		{
+			Kind:    DiagnosticAmbiguous,
+			Method:  http.MethodGet,
+			Path:    "/archive/:year?",
+			Other:   "/archive/latest",
+			Example: "/archive/latest",
+			Winner:  "/archive/latest",
+			Message: "GET /archive/:year? and GET /archive/latest both match /archive/latest, which is handled by /archive/latest",
+		},
+	}
+
+	diagnostics := r.Analyze()
+	assert(t, reflect.DeepEqual(diagnostics, expected), fmt.Sprintf("diagnostics > expected: %+v, got: %+v", expected, diagnostics))
+}
+
+func TestRouter_Analyze_NoDiagnostics(t *testing.T) {
+	tt := [][]string{
+		{"/users/:id<int>", "/users/me"},
+		{"/users/:id", "/users/:id/posts"},
+		{"/posts/:id<int>", "/posts/:slug<[a-z-]+>"},
+		{"/files/*path", "/static/*path"},
+		{"/users/:id/", "/users/:id/posts"},
+		{"/api/v1", "/API/v2"},
+	}
+
+	for _, paths := range tt {
+		t.Run(fmt.Sprint(paths), func(t *testing.T) {
+			r := newTestRouter()
+			for _, path := range paths {
+				r.GET(path, fakeHandler())
+			}
+			r.POST("/*rest", fakeHandler()) // Routes of different methods don't overlap.
+
+			diagnostics := r.Analyze()
+			assert(t, len(diagnostics) == 0, fmt.Sprintf("diagnostics > expected: [], got: %v", diagnostics))
+		})
+	}
+}
+
+func TestRouter_Analyze_CaseCollision(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/Users/:id", fakeHandler())
+	r.GET("/users/me", fakeHandler())
+
+	diagnostics := r.Analyze()
+	assert(t, len(diagnostics) == 1, fmt.Sprintf("diagnostics > expected: 1, got: %v", diagnostics))
+	d := diagnostics[0]
+	assert(t, d.Kind == DiagnosticCaseCollision, fmt.Sprintf("kind > expected: %s, got: %s", DiagnosticCaseCollision, d.Kind))
+
+	// The path correction of the example picks the reported winner.
+	r.UsePathCorrectionMatch(WithExecute())
+	match, ok := r.Serve().Lookup(http.MethodGet, d.Example)
+	assert(t, ok && match.Path == d.Winner, fmt.Sprintf("lookup %s > expected: %s, got: %s", d.Example, d.Winner, match.Path))
+	assert(t, match.Kind == MatchPathCorrection, fmt.Sprintf("match kind > expected: %s, got: %s", MatchPathCorrection, match.Kind))
+}
+
+func TestRouter_Analyze_ShadowedRoute(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/users/:id", fakeHandler())
+	r.GET("/users/:name<.+>", fakeHandler())
+
+	expected := []Diagnostic{
+		{
+			Kind:    DiagnosticShadowed,
+			Method:  http.MethodGet,
+			Path:    "/users/:id",
+			Other:   "/users/:name<.+>",
+			Example: "/users/1",
+			Winner:  "/users/:name<.+>",
+			Message: "GET /users/:id is never matched, since /users/:name<.+> takes the priority for the paths it matches, e.g.: /users/1",
+		},
+	}
+
+	diagnostics := r.Analyze()
+	assert(t, reflect.DeepEqual(diagnostics, expected), fmt.Sprintf("diagnostics > expected: %+v, got: %+v", expected, diagnostics))
+}
+
+func TestRouter_Analyze_CaseCollision_Overlapping(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/files/:name", fakeHandler())
+	r.GET("/files/*path", fakeHandler())
+	r.GET("/Files/:name", fakeHandler())
+
+	var collision *Diagnostic
+	for _, d := range r.Analyze() {
+		if d.Kind == DiagnosticCaseCollision && d.Path == "/files/:name" && d.Other == "/files/*path" {
+			d := d
+			collision = &d
+		}
+	}
+	assert(t, collision != nil, "case collision > expected: /files/:name and /files/*path, got: none")
+
+	// The path correction of the example picks a route other than the one handling the exact path.
+	r.UsePathCorrectionMatch(WithExecute())
+	match, ok := r.Serve().Lookup(http.MethodGet, collision.Example)
+	assert(t, ok && match.Path == collision.Winner, fmt.Sprintf("lookup %s > expected: %s, got: %s", collision.Example, collision.Winner, match.Path))
+	assert(t, match.Path != "/files/:name", fmt.Sprintf("lookup %s > expected a route other than /files/:name", collision.Example))
+}
@@ -0,0 +1,83 @@
+package shift
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// defaultAutoOptionsHandler replies with HTTP status 204 (No Content).
+func defaultAutoOptionsHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// headResponseWriter discards the response body written by the GET route serving a HEAD request.
+// The status code is held back until the handler returns, so that the 'Content-Length' header can report
+// the length of the discarded body.
+type headResponseWriter struct {
+	http.ResponseWriter
+	code        int
+	length      int
+	wroteHeader bool
+	flushed     bool // Denotes the status code has been written to the underlying http.ResponseWriter by Flush.
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.code = code
+	w.wroteHeader = true
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.length += len(b)
+	return len(b), nil
+}
+
+// Unwrap returns the underlying http.ResponseWriter. Used by http.ResponseController.
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush writes the held back status code and flushes the underlying http.ResponseWriter, so that streaming handlers
+// behave the same as for GET requests. The 'Content-Length' header is not reported once flushed.
+func (w *headResponseWriter) Flush() {
+	if !w.flushed {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.ResponseWriter.WriteHeader(w.code)
+		w.flushed = true
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// flush writes the held back status code along with the 'Content-Length' header
+// unless the handler has set the 'Content-Length' or the 'Transfer-Encoding' header.
+func (w *headResponseWriter) flush() {
+	if w.flushed {
+		return
+	}
+
+	if !w.wroteHeader {
+		w.code = http.StatusOK
+	}
+
+	header := w.ResponseWriter.Header()
+	if bodyAllowed(w.code) && header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" {
+		header.Set("Content-Length", strconv.Itoa(w.length))
+	}
+
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// bodyAllowed reports whether a response with the status code is permitted to have a body.
+func bodyAllowed(code int) bool {
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
+}
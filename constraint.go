@@ -0,0 +1,168 @@
+package shift
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ConstraintFunc reports whether the param value satisfies the constraint.
+//
+// A ConstraintFunc is executed on every request that reaches the constrained param, so it should be fast
+// and allocation free.
+type ConstraintFunc func(value string) bool
+
+// builtInConstraints are the typed constraints available to every router.
+// Typed constraints registered with Router.UseParamConstraint take precedence over the built-in constraints.
+var builtInConstraints = map[string]ConstraintFunc{
+	"int":   isInt,
+	"uint":  isUint,
+	"alpha": isAlpha,
+	"alnum": isAlnum,
+	"uuid":  isUUID,
+	"date":  isDate,
+}
+
+// paramConstraint restricts the values a param node accepts.
+type paramConstraint struct {
+	expr  string // Raw constraint expression as written in the route. e.g.: int, [a-z]+
+	match ConstraintFunc
+}
+
+// compileConstraint resolves the constraint expression into a paramConstraint.
+// The expression is first looked up in the registry and the built-in constraints. If not found, it's compiled as a
+// regular expression which must match the whole param value.
+func compileConstraint(expr string, registry map[string]ConstraintFunc) *paramConstraint {
+	if fn, ok := registry[expr]; ok {
+		return &paramConstraint{expr, fn}
+	}
+
+	if fn, ok := builtInConstraints[expr]; ok {
+		return &paramConstraint{expr, fn}
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic(fmt.Sprintf("invalid param constraint <%s>: %v", expr, err))
+	}
+
+	return &paramConstraint{expr, re.MatchString}
+}
+
+// splitParam splits a param segment into the param name and the constraint expression.
+//
+//	e.g.:
+//	:id         -> id, ""
+//	:id<int>    -> id, int
+//	:n<[a-z]+>  -> n, [a-z]+
+//	:m<int>?    -> m, int
+func splitParam(seg string) (name string, expr string) {
+	seg = seg[1:] // Skip ':' prefix.
+	if isOptionalParam(seg) {
+		seg = seg[:len(seg)-1]
+	}
+
+	idx := strings.IndexByte(seg, '<')
+	if idx == -1 {
+		return seg, ""
+	}
+
+	return seg[:idx], seg[idx+1 : len(seg)-1]
+}
+
+func isInt(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isUint(s)
+}
+
+func isUint(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9') && !(c|0x20 >= 'a' && c|0x20 <= 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s is in the canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'f'
+}
+
+// isDate reports whether s is a valid calendar date in the YYYY-MM-DD form.
+func isDate(s string) bool {
+	if len(s) != 10 || s[4] != '-' || s[7] != '-' {
+		return false
+	}
+
+	if !isUint(s[:4]) || !isUint(s[5:7]) || !isUint(s[8:]) {
+		return false
+	}
+
+	year := int(s[0]-'0')*1000 + int(s[1]-'0')*100 + int(s[2]-'0')*10 + int(s[3]-'0')
+	month := int(s[5]-'0')*10 + int(s[6]-'0')
+	day := int(s[8]-'0')*10 + int(s[9]-'0')
+
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+
+	days := [...]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}[month-1]
+	if month == 2 && year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		days = 29
+	}
+
+	return day <= days
+}
@@ -0,0 +1,91 @@
+package shift
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBuiltInConstraints(t *testing.T) {
+	tt := []struct {
+		constraint string
+		value      string
+		valid      bool
+	}{
+		{"int", "42", true},
+		{"int", "-42", true},
+		{"int", "+42", true},
+		{"int", "-", false},
+		{"int", "4.2", false},
+		{"int", "", false},
+		{"uint", "42", true},
+		{"uint", "-42", false},
+		{"alpha", "Gopher", true},
+		{"alpha", "gopher1", false},
+		{"alpha", "go[her", false},
+		{"alnum", "Gopher1", true},
+		{"alnum", "gopher-1", false},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123E4567-E89B-12D3-A456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"uuid", "123e4567-e89b-12d3-a456-42661417400g", false},
+		{"date", "2023-12-31", true},
+		{"date", "2024-02-29", true},
+		{"date", "2023-02-29", false},
+		{"date", "1900-02-29", false},
+		{"date", "2000-02-29", true},
+		{"date", "2023-13-01", false},
+		{"date", "2023-04-31", false},
+		{"date", "2023-00-10", false},
+		{"date", "2023/12/31", false},
+	}
+
+	for _, tx := range tt {
+		t.Run(fmt.Sprintf("%s/%s", tx.constraint, tx.value), func(t *testing.T) {
+			valid := compileConstraint(tx.constraint, nil).match(tx.value)
+			assert(t, valid == tx.valid, fmt.Sprintf("<%s> %s > expected: %v, got: %v", tx.constraint, tx.value, tx.valid, valid))
+		})
+	}
+}
+
+func TestCompileConstraint(t *testing.T) {
+	t.Run("registry takes precedence", func(t *testing.T) {
+		c := compileConstraint("int", map[string]ConstraintFunc{
+			"int": func(value string) bool { return value == "one" },
+		})
+		assert(t, c.match("one"), "expected the registered constraint to match")
+		assert(t, !c.match("1"), "expected the built-in constraint to be overridden")
+	})
+
+	t.Run("regular expression matches whole value", func(t *testing.T) {
+		c := compileConstraint("[a-z]+|[0-9]+", nil)
+		assert(t, c.match("abc"), "expected abc to match")
+		assert(t, c.match("123"), "expected 123 to match")
+		assert(t, !c.match("abc123"), "expected abc123 not to match")
+	})
+
+	t.Run("invalid regular expression", func(t *testing.T) {
+		rec := panicHandler(func() {
+			compileConstraint("[a-z", nil)
+		})
+		assert(t, rec != nil, "expected to panic")
+	})
+}
+
+func TestSplitParam(t *testing.T) {
+	tt := []struct {
+		seg  string
+		name string
+		expr string
+	}{
+		{":id", "id", ""},
+		{":id<int>", "id", "int"},
+		{":name<[a-z0-9_-]+>", "name", "[a-z0-9_-]+"},
+		{":name<(?P<x>a+)>", "name", "(?P<x>a+)"},
+	}
+
+	for _, tx := range tt {
+		name, expr := splitParam(tx.seg)
+		assert(t, name == tx.name, fmt.Sprintf("%s name > expected: %s, got: %s", tx.seg, tx.name, name))
+		assert(t, expr == tx.expr, fmt.Sprintf("%s expr > expected: %s, got: %s", tx.seg, tx.expr, expr))
+	}
+}
@@ -1,21 +1,40 @@
 package shift
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+)
 
 type routeLog struct {
-	method  string
-	path    string
-	handler HandlerFunc
+	method   string
+	path     string
+	handler  HandlerFunc
+	name     string
+	host     string
+	mounted  []RouteInfo // Non-nil for the routes of a mounted Router. It replaces the route in the route listings.
+	meta     *RouteMeta
+	matchers []Matcher        // Conditions the request must satisfy to select the route. See Core.When.
+	scope    *scope           // Scope the route has been registered within.
+	mws      []MiddlewareFunc // Middleware stack at the time of the registration. Resolved middleware stack once the Router is served.
+	implicit bool             // Denotes the route gives way to a route registered later for the same method, host and path. See Core.HandlePattern.
 }
 
 // Core provides methods to register routes.
 type Core struct {
-	base string
-	logs *[]routeLog
-	mws  []MiddlewareFunc
+	base     string
+	logs     *[]routeLog
+	misses   *[]missLog
+	scope    *scope
+	mws      []MiddlewareFunc
+	name     string
+	host     string
+	meta     *RouteMeta
+	matchers []Matcher
 }
 
 // Group groups routes together at the given path with a group-scoped middleware stack inherited from the parent middleware stack.
+// Middlewares attached to the parent middleware stack after creating the group apply to the group's routes as well,
+// unless Router.UsePositionalMiddlewares is enabled.
 // It provides the opportunity to maintain groups of routes in different files using the func(g *Group) func signature.
 //
 // It is also possible to nest groups within groups.
@@ -24,9 +43,14 @@ func (c *Core) Group(path string, fn func(g *Group)) {
 	copy(stack, c.mws)
 
 	fn(&Group{Core{
-		logs: c.logs,
-		base: c.base + path,
-		mws:  stack,
+		logs:     c.logs,
+		misses:   c.misses,
+		scope:    &scope{parent: c.scope},
+		base:     c.base + path,
+		mws:      stack,
+		host:     c.host,
+		meta:     c.meta,
+		matchers: c.matchers,
 	}})
 }
 
@@ -38,10 +62,43 @@ func (c *Core) With(middlewares ...MiddlewareFunc) *Core {
 	copy(stack, c.mws)
 	stack = append(stack, middlewares...)
 
+	mws := make([]MiddlewareFunc, len(middlewares))
+	copy(mws, middlewares)
+
 	return &Core{
 		c.base,
 		c.logs,
+		c.misses,
+		&scope{parent: c.scope, mws: mws},
 		stack,
+		c.name,
+		c.host,
+		c.meta,
+		c.matchers,
+	}
+}
+
+// Name returns an instance which names the routes registered through it.
+// Named routes can be used to build URLs using Server.URL or Router.URLFor.
+//
+//	router.Name("user.posts").GET("/users/:id/posts/*rest", handler)
+//
+// A name can be shared by multiple HTTP methods of the same path, but not by different paths.
+func (c *Core) Name(name string) *Core {
+	if name == "" {
+		panic("route name cannot be empty")
+	}
+
+	return &Core{
+		c.base,
+		c.logs,
+		c.misses,
+		c.scope,
+		c.mws,
+		name,
+		c.host,
+		c.meta,
+		c.matchers,
 	}
 }
 
@@ -55,15 +112,41 @@ func (c *Core) Map(methods []string, path string, handler HandlerFunc) {
 		panic("handler cannot be nil")
 	}
 
+	if c.name != "" {
+		for _, log := range *c.logs {
+			if log.name == c.name && log.path != c.base+path {
+				panic(fmt.Sprintf("route name %s already registered for %s", c.name, log.path))
+			}
+		}
+	}
+
 	for _, meth := range methods {
+		c.removeImplicit(meth, c.base+path)
 		*c.logs = append(*c.logs, routeLog{
-			method:  meth,
-			path:    c.base + path,
-			handler: c.chain(handler),
+			method:   meth,
+			path:     c.base + path,
+			handler:  handler,
+			name:     c.name,
+			host:     c.host,
+			meta:     c.meta,
+			matchers: c.matchers,
+			scope:    c.scope,
+			mws:      c.mws[:len(c.mws):len(c.mws)],
 		})
 	}
 }
 
+// removeImplicit removes the implicit route registered for the method and the path within the host, if any.
+func (c *Core) removeImplicit(method string, path string) {
+	logs := *c.logs
+	for i, log := range logs {
+		if log.implicit && log.method == method && log.host == c.host && log.path == path {
+			*c.logs = append(logs[:i], logs[i+1:]...)
+			return
+		}
+	}
+}
+
 // GET maps a request handler for the GET method at the given path.
 // It is a shorthand for:
 //
@@ -143,10 +226,3 @@ func (c *Core) TRACE(path string, handler HandlerFunc) {
 func (c *Core) All(path string, handler HandlerFunc) {
 	c.Map([]string{""}, path, handler)
 }
-
-func (c *Core) chain(handler HandlerFunc) HandlerFunc {
-	for i := len(c.mws) - 1; i >= 0; i-- {
-		handler = c.mws[i](handler)
-	}
-	return handler
-}
@@ -0,0 +1,262 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins is the list of origins permitted to make cross-origin requests.
+	// An origin is either an exact origin (https://example.com), a wildcard subdomain origin (https://*.example.com)
+	// or '*' to permit any origin.
+	AllowOrigins []string
+
+	// AllowOriginFunc reports whether the origin is permitted to make cross-origin requests.
+	// It is consulted when the origin doesn't match AllowOrigins.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowHeaders is the list of request headers permitted in cross-origin requests.
+	// If empty, the headers requested by the preflight request are permitted.
+	AllowHeaders []string
+
+	// ExposeHeaders is the list of response headers exposed to the client.
+	ExposeHeaders []string
+
+	// AllowCredentials permits cross-origin requests to include credentials such as cookies.
+	// It cannot be combined with the '*' origin, since it would let any site make credentialed requests. Permit the
+	// origins explicitly (or through AllowOriginFunc) instead.
+	AllowCredentials bool
+
+	// MaxAge is the number of seconds the result of a preflight request can be cached. Not sent when zero.
+	MaxAge int
+}
+
+// CORS handles Cross-Origin Resource Sharing for the routes it's attached to.
+//
+// Preflight requests are replied with HTTP status 204 (No Content) without executing the subsequent middlewares in the chain
+// and the request handler. 'Access-Control-Allow-Methods' lists the HTTP methods registered for the requested path.
+//
+// It is not necessary to register OPTIONS routes for the preflight requests. When the path doesn't have an OPTIONS route,
+// preflight requests are executed through the middleware stack of the route registered for the requested method.
+// Therefore, CORS can be scoped to a Group or a route using Group.Use or Core.With.
+//
+//	router.With(shift.CORS(shift.CORSConfig{
+//		AllowOrigins: []string{"https://example.com", "https://*.example.com"},
+//		MaxAge:       600,
+//	})).GET("/users", ListUsers)
+//
+// CORS panics if AllowOrigins contains '*' while AllowCredentials is enabled.
+func CORS(config CORSConfig) MiddlewareFunc {
+	origins := newOriginMatcher(config.AllowOrigins, config.AllowOriginFunc)
+	if origins.any && config.AllowCredentials {
+		panic("origin * cannot be allowed with credentials")
+	}
+
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.Itoa(config.MaxAge)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			origin := r.Header.Get("Origin")
+			preflight := isPreflight(r)
+
+			header := w.Header()
+			if !origins.any {
+				header.Add("Vary", "Origin")
+			}
+
+			if preflight {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !origins.match(origin) {
+				if preflight {
+					// Reply without the CORS headers so that the client rejects the cross-origin request.
+					w.WriteHeader(http.StatusNoContent)
+					return nil
+				}
+				return next(w, r, route)
+			}
+
+			if origins.any {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if config.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					header.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				return next(w, r, route)
+			}
+
+			header.Set("Access-Control-Allow-Methods", allowedMethods(r, route))
+
+			if allowHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", allowHeaders)
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+
+			if maxAge != "" {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+	}
+}
+
+// isPreflight reports whether the request is a CORS preflight request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// allowedMethods returns the HTTP methods registered for the requested path.
+// Falls back to the requested method when the route has not been dispatched by a Server.
+func allowedMethods(r *http.Request, route Route) string {
+	if route.svr == nil {
+		return r.Header.Get("Access-Control-Request-Method")
+	}
+
+	return route.svr.allowedHeader(route.svr.requestPath(r), "")
+}
+
+// originMatcher matches origins against the exact origins, the wildcard subdomain origins and the predicate.
+type originMatcher struct {
+	any       bool
+	exact     []string
+	wildcards [][2]string // Prefix and suffix surrounding the '*'.
+	fn        func(origin string) bool
+}
+
+func newOriginMatcher(origins []string, fn func(origin string) bool) *originMatcher {
+	m := &originMatcher{fn: fn}
+
+	for _, origin := range origins {
+		switch strings.Count(origin, "*") {
+		case 0:
+			m.exact = append(m.exact, origin)
+		case 1:
+			if origin == "*" {
+				m.any = true
+				continue
+			}
+
+			i := strings.IndexByte(origin, '*')
+			if !strings.HasPrefix(origin[i+1:], ".") {
+				panic(fmt.Sprintf("origin %s > wildcard must be followed by a dot", origin))
+			}
+			m.wildcards = append(m.wildcards, [2]string{origin[:i], origin[i+1:]})
+		default:
+			panic(fmt.Sprintf("origin %s > only one wildcard is allowed", origin))
+		}
+	}
+
+	return m
+}
+
+// match reports whether the origin is permitted. Origins are compared case-insensitively.
+func (m *originMatcher) match(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	for _, exact := range m.exact {
+		if strings.EqualFold(exact, origin) {
+			return true
+		}
+	}
+
+	for _, wc := range m.wildcards {
+		prefix, suffix := wc[0], wc[1]
+		if len(origin) > len(prefix)+len(suffix) &&
+			strings.EqualFold(origin[:len(prefix)], prefix) &&
+			strings.EqualFold(origin[len(origin)-len(suffix):], suffix) {
+			return true
+		}
+	}
+
+	return m.fn != nil && m.fn(origin)
+}
+
+// populatePreflightRoutes registers the routes executing CORS preflight requests through the middleware stack of
+// the routes. Preflight routes are not registered when none of the routes has a middleware stack.
+func (svr *Server) populatePreflightRoutes(logs []routeLog) {
+	preflightLogs := make([]routeLog, 0, len(logs))
+	hasMiddlewares := false
+
+	seen := map[string]bool{} // Preflight requests don't select between the routes registered with matchers.
+	for _, log := range logs {
+		key := log.method + " " + log.path
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		log.matchers = nil
+		log.handler = chain(log.mws, svr.handlePreflightMiss)
+		if len(log.mws) > 0 {
+			hasMiddlewares = true
+		}
+		preflightLogs = append(preflightLogs, log)
+	}
+
+	if !hasMiddlewares {
+		return
+	}
+
+	svr.preflight = map[string]multiplexer{}
+	for method, info := range groupLogsByMethods(preflightLogs) {
+		svr.preflight[method] = svr.newMux(info)
+	}
+}
+
+// dispatchPreflight executes the preflight request through the middleware stack of the route registered for the requested method.
+// Returns false if the request is not a preflight request or a route was not found.
+func (svr *Server) dispatchPreflight(w http.ResponseWriter, r *http.Request, path string, hostPs *internalParams) bool {
+	if !isPreflight(r) {
+		return false
+	}
+
+	mux := svr.preflight[r.Header.Get("Access-Control-Request-Method")]
+	if mux == nil {
+		return false
+	}
+
+	handler, ps, template, meta := mux.find(path)
+	if handler == nil {
+		return false
+	}
+
+	_ = handler(w, r, Route{
+		Params: Params{ps, hostPs, svr.config.decodeParams()},
+		Path:   template,
+		Meta:   meta,
+		svr:    svr,
+	})
+	return true
+}
+
+// handlePreflightMiss replies to the preflight request which passed through the middleware stack of the route
+// as if a route was not found.
+func (svr *Server) handlePreflightMiss(w http.ResponseWriter, r *http.Request, route Route) error {
+	path := svr.requestPath(r)
+	svr.handleMiss(w, r, path, route.Params.host, svr.missAllowed(path, r.Method))
+	return nil
+}
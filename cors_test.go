@@ -0,0 +1,232 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCORS_Origins(t *testing.T) {
+	r := New()
+	r.Use(CORS(CORSConfig{
+		AllowOrigins: []string{"https://example.com", "https://*.example.org"},
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".internal")
+		},
+		ExposeHeaders: []string{"X-Request-Id", "X-Total"},
+	}))
+	r.GET("/foo", fakeHandler())
+	srv := r.Serve()
+
+	tt := []struct {
+		origin  string
+		allowed bool
+	}{
+		{origin: "https://example.com", allowed: true},
+		{origin: "https://EXAMPLE.com", allowed: true},
+		{origin: "http://example.com", allowed: false},
+		{origin: "https://api.example.org", allowed: true},
+		{origin: "https://a.b.example.org", allowed: true},
+		{origin: "https://example.org", allowed: false},
+		{origin: "https://.example.org", allowed: false},
+		{origin: "http://svc.internal", allowed: true},
+		{origin: "https://evil.com", allowed: false},
+		{origin: "", allowed: false},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.origin, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/foo", nil)
+			if tx.origin != "" {
+				req.Header.Set("Origin", tx.origin)
+			}
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+
+			expected, expose := "", ""
+			if tx.allowed {
+				expected, expose = tx.origin, "X-Request-Id, X-Total"
+			}
+
+			allowOrigin := rw.Header().Get("Access-Control-Allow-Origin")
+			assert(t, allowOrigin == expected, fmt.Sprintf("allow origin > expected: %s, got: %s", expected, allowOrigin))
+			exposeHeaders := rw.Header().Get("Access-Control-Expose-Headers")
+			assert(t, exposeHeaders == expose, fmt.Sprintf("expose headers > expected: %s, got: %s", expose, exposeHeaders))
+			vary := rw.Header().Get("Vary")
+			assert(t, vary == "Origin", fmt.Sprintf("vary > expected: Origin, got: %s", vary))
+		})
+	}
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	r := New()
+	r.Use(CORS(CORSConfig{AllowOrigins: []string{"*"}}))
+	r.GET("/foo", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/foo", nil)
+	req.Header.Set("Origin", "https://example.com")
+	r.Serve().ServeHTTP(rw, req)
+
+	allowOrigin := rw.Header().Get("Access-Control-Allow-Origin")
+	assert(t, allowOrigin == "*", fmt.Sprintf("allow origin > expected: *, got: %s", allowOrigin))
+
+	credentials := rw.Header().Get("Access-Control-Allow-Credentials")
+	assert(t, credentials == "", fmt.Sprintf("allow credentials > expected: empty, got: %s", credentials))
+
+	vary := rw.Header().Get("Vary")
+	assert(t, vary == "", fmt.Sprintf("vary > expected: empty, got: %s", vary))
+}
+
+func TestCORS_AnyOriginWithCredentials(t *testing.T) {
+	pnk := panicHandler(func() {
+		CORS(CORSConfig{AllowOrigins: []string{"https://example.com", "*"}, AllowCredentials: true})
+	})
+	assert(t, pnk != nil, "expected a panic")
+
+	// Credentials are allowed for the origins permitted explicitly.
+	r := New()
+	r.Use(CORS(CORSConfig{AllowOriginFunc: func(origin string) bool { return true }, AllowCredentials: true}))
+	r.GET("/foo", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/foo", nil)
+	req.Header.Set("Origin", "https://example.com")
+	r.Serve().ServeHTTP(rw, req)
+
+	allowOrigin := rw.Header().Get("Access-Control-Allow-Origin")
+	assert(t, allowOrigin == "https://example.com", fmt.Sprintf("allow origin > expected: https://example.com, got: %s", allowOrigin))
+	credentials := rw.Header().Get("Access-Control-Allow-Credentials")
+	assert(t, credentials == "true", fmt.Sprintf("allow credentials > expected: true, got: %s", credentials))
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	r := New()
+	r.GET("/public", fakeHandler())
+
+	executed := false
+	r.Group("/api", func(g *Group) {
+		g.Use(CORS(CORSConfig{
+			AllowOrigins: []string{"https://example.com"},
+			MaxAge:       600,
+		}))
+		g.GET("/users/:id", fakeHandler())
+		g.PUT("/users/:id", fakeHandler())
+		g.OPTIONS("/explicit", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			executed = true
+			return nil
+		})
+		g.POST("/explicit", fakeHandler())
+	})
+	r.With(CORS(CORSConfig{
+		AllowOrigins: []string{"https://example.com"},
+		AllowHeaders: []string{"Content-Type", "Authorization"},
+	})).DELETE("/posts/:id", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		name           string
+		path           string
+		origin         string
+		method         string
+		requestHeaders string
+		code           int
+		allowOrigin    string
+		allowMethods   string
+		allowHeaders   string
+		maxAge         string
+	}{
+		{
+			name: "group", path: "/api/users/1", origin: "https://example.com", method: http.MethodPut, requestHeaders: "X-Foo",
+			code: http.StatusNoContent, allowOrigin: "https://example.com", allowMethods: "GET, PUT", allowHeaders: "X-Foo", maxAge: "600",
+		},
+		{
+			name: "route", path: "/posts/1", origin: "https://example.com", method: http.MethodDelete, requestHeaders: "X-Foo",
+			code: http.StatusNoContent, allowOrigin: "https://example.com", allowMethods: "DELETE", allowHeaders: "Content-Type, Authorization",
+		},
+		{
+			name: "disallowed origin", path: "/api/users/1", origin: "https://evil.com", method: http.MethodGet,
+			code: http.StatusNoContent,
+		},
+		{
+			name: "explicit options route", path: "/api/explicit", origin: "https://example.com", method: http.MethodPost,
+			code: http.StatusNoContent, allowOrigin: "https://example.com", allowMethods: "POST, OPTIONS", maxAge: "600",
+		},
+		{
+			name: "unregistered method", path: "/posts/1", origin: "https://example.com", method: http.MethodPatch,
+			code: http.StatusNotFound,
+		},
+		{
+			name: "without cors", path: "/public", origin: "https://example.com", method: http.MethodGet,
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodOptions, tx.path, nil)
+			req.Header.Set("Origin", tx.origin)
+			req.Header.Set("Access-Control-Request-Method", tx.method)
+			if tx.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tx.requestHeaders)
+			}
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+
+			h := rw.Header()
+			assert(t, h.Get("Access-Control-Allow-Origin") == tx.allowOrigin, fmt.Sprintf("allow origin > expected: %s, got: %s", tx.allowOrigin, h.Get("Access-Control-Allow-Origin")))
+			assert(t, h.Get("Access-Control-Allow-Methods") == tx.allowMethods, fmt.Sprintf("allow methods > expected: %s, got: %s", tx.allowMethods, h.Get("Access-Control-Allow-Methods")))
+			assert(t, h.Get("Access-Control-Allow-Headers") == tx.allowHeaders, fmt.Sprintf("allow headers > expected: %s, got: %s", tx.allowHeaders, h.Get("Access-Control-Allow-Headers")))
+			assert(t, h.Get("Access-Control-Max-Age") == tx.maxAge, fmt.Sprintf("max age > expected: %s, got: %s", tx.maxAge, h.Get("Access-Control-Max-Age")))
+		})
+	}
+
+	assert(t, !executed, "expected preflight request not to execute the OPTIONS request handler")
+}
+
+func TestCORS_Preflight_AutoOptions(t *testing.T) {
+	r := New()
+	r.UseAutoOptions(nil)
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			w.Header().Set("X-Middleware", "true")
+			return next(w, r, route)
+		}
+	})
+	r.GET("/foo", fakeHandler())
+
+	// The preflight request passes through the middleware stack and reaches the automatic OPTIONS handler.
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodOptions, "/foo", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	r.Serve().ServeHTTP(rw, req)
+
+	assert(t, rw.Code == http.StatusNoContent, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusNoContent, rw.Code))
+	assert(t, rw.Header().Get("X-Middleware") == "true", "expected preflight request to execute the middleware stack")
+	assert(t, rw.Header().Get("Allow") == "GET, OPTIONS", fmt.Sprintf("allow header > expected: GET, OPTIONS, got: %s", rw.Header().Get("Allow")))
+}
+
+func TestCORS_InvalidOrigins(t *testing.T) {
+	tt := []string{
+		"https://*example.com",
+		"https://*.*.example.com",
+	}
+
+	for _, origin := range tt {
+		t.Run(origin, func(t *testing.T) {
+			defer func() {
+				assert(t, recover() != nil, "expected a panic")
+			}()
+
+			CORS(CORSConfig{AllowOrigins: []string{origin}})
+		})
+	}
+}
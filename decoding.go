@@ -0,0 +1,111 @@
+package shift
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PathDecoding describes how the request path is matched against the routes and how the param values are decoded.
+// See Router.UsePathDecoding.
+type PathDecoding uint8
+
+const (
+	// PathDecodingNone matches the routes against the request's URL.RawPath when set, otherwise against URL.Path.
+	// Param values are not decoded. Thus, a param value is percent-encoded only if URL.RawPath is set.
+	// This is the default mode.
+	PathDecodingNone PathDecoding = iota
+
+	// PathDecodingParams matches the routes against the escaped path (URL.EscapedPath) and decodes the param values.
+	// An encoded slash (%2F) within a param value doesn't split the segment,
+	// e.g.: /files/a%2Fb matches /files/:name with the param value a/b.
+	// Param values are decoded exactly once, e.g.: /files/a%2541 matches /files/:name with the param value a%41.
+	PathDecodingParams
+
+	// PathDecodingPath matches the routes against the decoded URL.Path.
+	// e.g.: /files/a%2Fb matches /files/:dir/:name with the param values a and b.
+	PathDecodingPath
+
+	// PathDecodingRejectEncodedSlash is same as PathDecodingPath, except it replies HTTP 400 (http.StatusBadRequest)
+	// status to the requests containing an encoded slash (%2F) in the path.
+	PathDecodingRejectEncodedSlash
+)
+
+// UsePathDecoding sets the mode to match the request path and decode the param values.
+// Defaults to PathDecodingNone.
+//
+// Param values are decoded lazily when accessed. Therefore, accessing param values without percent-encoded
+// characters doesn't allocate.
+func (r *Router) UsePathDecoding(mode PathDecoding) {
+	if mode > PathDecodingRejectEncodedSlash {
+		panic("invalid path decoding mode")
+	}
+
+	r.config.pathDecoding = mode
+}
+
+// matchPath returns the request path to match against the routes.
+// Returns false as the second return value if the request must be rejected.
+func (config *Config) matchPath(r *http.Request) (string, bool) {
+	switch config.pathDecoding {
+	case PathDecodingPath:
+		return r.URL.Path, true
+	case PathDecodingRejectEncodedSlash:
+		if r.URL.RawPath != "" && hasEncodedSlash(r.URL.RawPath) {
+			return "", false
+		}
+		return r.URL.Path, true
+	case PathDecodingParams:
+		return r.URL.EscapedPath(), true
+	default:
+		if r.URL.RawPath != "" {
+			return r.URL.RawPath, true
+		}
+		return r.URL.Path, true
+	}
+}
+
+// requestPath returns the path used to match the routes.
+func (svr *Server) requestPath(r *http.Request) string {
+	path, _ := svr.config.matchPath(r)
+	return path
+}
+
+// decodeParams reports whether the param values must be decoded when accessed.
+func (config *Config) decodeParams() bool {
+	return config.pathDecoding == PathDecodingParams
+}
+
+// matchesEscapedPath reports whether the request path is matched against the routes escaped.
+func (config *Config) matchesEscapedPath(r *http.Request) bool {
+	switch config.pathDecoding {
+	case PathDecodingNone:
+		return r.URL.RawPath != ""
+	case PathDecodingParams:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasEncodedSlash(path string) bool {
+	for i := 0; i+2 < len(path); i++ {
+		if path[i] == '%' && path[i+1] == '2' && (path[i+2] == 'F' || path[i+2] == 'f') {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeParam returns the decoded param value.
+// The value is returned as is if it doesn't contain percent-encoded characters or is not a valid percent-encoding.
+func unescapeParam(value string) string {
+	if strings.IndexByte(value, '%') == -1 {
+		return value
+	}
+
+	if v, err := url.PathUnescape(value); err == nil {
+		return v
+	}
+	return value
+}
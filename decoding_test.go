@@ -0,0 +1,111 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouter_UsePathDecoding(t *testing.T) {
+	echo := func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "%s %v", route.Path, route.Params.Map())
+		return nil
+	}
+
+	tt := []struct {
+		name string
+		mode PathDecoding
+		path string
+		code int
+		body string
+	}{
+		{name: "none encoded slash", mode: PathDecodingNone, path: "/files/a%2Fb", code: 200, body: "/files/:name map[name:a%2Fb]"},
+		{name: "none encoded space", mode: PathDecodingNone, path: "/files/a%20b", code: 200, body: "/files/:name map[name:a b]"},
+		{name: "params encoded slash", mode: PathDecodingParams, path: "/files/a%2Fb", code: 200, body: "/files/:name map[name:a/b]"},
+		{name: "params encoded space", mode: PathDecodingParams, path: "/files/a%20b", code: 200, body: "/files/:name map[name:a b]"},
+		{name: "params plain", mode: PathDecodingParams, path: "/files/a", code: 200, body: "/files/:name map[name:a]"},
+		{name: "params encoded percent", mode: PathDecodingParams, path: "/files/a%2541", code: 200, body: "/files/:name map[name:a%41]"},
+		{name: "params encoded percent and slash", mode: PathDecodingParams, path: "/files/a%2541%2Fb", code: 200, body: "/files/:name map[name:a%41/b]"},
+		{name: "path encoded slash", mode: PathDecodingPath, path: "/files/a%2Fb", code: 200, body: "/files/:dir/:name map[dir:a name:b]"},
+		{name: "path encoded space", mode: PathDecodingPath, path: "/files/a%20b", code: 200, body: "/files/:name map[name:a b]"},
+		{name: "reject encoded slash", mode: PathDecodingRejectEncodedSlash, path: "/files/a%2fb", code: 400},
+		{name: "reject encoded space", mode: PathDecodingRejectEncodedSlash, path: "/files/a%20b", code: 200, body: "/files/:name map[name:a b]"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			r := New()
+			r.UsePathDecoding(tx.mode)
+			r.GET("/files/:name", echo)
+			r.GET("/files/:dir/:name", echo)
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tx.path, nil)
+			r.Serve().ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.code == 200 {
+				assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			}
+		})
+	}
+}
+
+func TestRouter_UsePathDecoding_Accessors(t *testing.T) {
+	r := New()
+	r.UsePathDecoding(PathDecodingParams)
+	r.GET("/files/:name/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		v, _ := route.Params.Lookup("name")
+		cp := route.Params.Copy()
+
+		var each string
+		route.Params.ForEach(func(k, v string) {
+			each += k + "=" + v + ";"
+		})
+
+		_, _ = fmt.Fprintf(w, "%s|%s|%d|%s|%v|%s", route.Params.Get("name"), v, route.Params.MustInt("id"), cp.Get("name"), route.Params.Slice(), each)
+		return nil
+	})
+
+	srv := r.Serve()
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/files/a%2Fb%20c/4%32", nil)
+	srv.ServeHTTP(rw, req)
+
+	expected := "a/b c|a/b c|42|a/b c|[{name a/b c} {id 42}]|name=a/b c;id=42;"
+	assert(t, rw.Body.String() == expected, fmt.Sprintf("body > expected: %s, got: %s", expected, rw.Body.String()))
+
+	match, ok := srv.Lookup(http.MethodGet, "/files/a%2Fb/1")
+	assert(t, ok && match.Params.Get("name") == "a/b", fmt.Sprintf("lookup > expected: a/b, got: %s", match.Params.Get("name")))
+}
+
+func TestRouter_UsePathDecoding_Mount(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s|%s", r.URL.Path, r.URL.RawPath)
+	})
+
+	tt := []struct {
+		mode PathDecoding
+		body string
+	}{
+		{mode: PathDecodingNone, body: "/a/b c|/a%2Fb%20c"},
+		{mode: PathDecodingParams, body: "/a/b c|/a%2Fb%20c"},
+		{mode: PathDecodingPath, body: "/a/b c|"},
+	}
+
+	for _, tx := range tt {
+		t.Run(fmt.Sprint(tx.mode), func(t *testing.T) {
+			r := New()
+			r.UsePathDecoding(tx.mode)
+			r.Mount("/legacy", echo)
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/legacy/a%2Fb%20c", nil)
+			r.Serve().ServeHTTP(rw, req)
+
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+		})
+	}
+}
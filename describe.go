@@ -0,0 +1,371 @@
+package shift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ServerDescription describes the routing structures of a Server. See Server.Describe.
+type ServerDescription struct {
+	Routes  []RouteDescription  `json:"routes"`
+	Methods []MethodDescription `json:"methods"`
+	Hosts   []HostDescription   `json:"hosts,omitempty"` // In the order the host patterns are matched.
+}
+
+// RouteDescription describes a registered route.
+type RouteDescription struct {
+	Method      string   `json:"method"` // Empty for the routes registered with All.
+	Path        string   `json:"path"`
+	Name        string   `json:"name,omitempty"`
+	Host        string   `json:"host,omitempty"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// MethodDescription describes the multiplexer which dispatches the requests of an HTTP method.
+type MethodDescription struct {
+	Method string `json:"method"`
+
+	// Mux is the multiplexer variant chosen based on the ratio of the static routes. static, hybrid or radix.
+	Mux string `json:"mux"`
+
+	// Static lists the paths of the routes stored in the map of the static and hybrid multiplexers.
+	Static []string `json:"static,omitempty"`
+
+	// Tree is the radix tree of the hybrid and radix multiplexers.
+	Tree *NodeDescription `json:"tree,omitempty"`
+}
+
+// HostDescription describes the routing structures of the routes registered for a host pattern.
+type HostDescription struct {
+	Pattern string              `json:"pattern"`
+	Methods []MethodDescription `json:"methods"`
+}
+
+// NodeDescription describes a node of a radix tree. Children are listed in the order they are searched.
+type NodeDescription struct {
+	Kind       string            `json:"kind"`   // static, param or wildcard.
+	Prefix     string            `json:"prefix"` // Path segment stored in the node. Param and wildcard nodes are denoted by : and *.
+	Constraint string            `json:"constraint,omitempty"`
+	Route      string            `json:"route,omitempty"` // Path template of the route ending at the node, if any.
+	Children   []NodeDescription `json:"children,omitempty"`
+}
+
+// Describe returns the description of the routes and the multiplexers the Server was generated with.
+// It's useful to debug the routing, e.g.: to find out how the node prefixes were split. See DebugHandler.
+//
+//	srv := router.Serve()
+//	_ = srv.Describe().WriteText(os.Stdout)
+func (svr *Server) Describe() ServerDescription {
+	d := ServerDescription{
+		Routes:  make([]RouteDescription, 0, len(svr.routes)),
+		Methods: svr.describeMethods(),
+	}
+
+	for _, route := range svr.routes {
+		d.Routes = append(d.Routes, RouteDescription{
+			Method:      route.Method,
+			Path:        route.Path,
+			Name:        route.Name,
+			Host:        route.Host,
+			Middlewares: append([]string(nil), route.Middlewares...),
+			Tags:        append([]string(nil), route.Tags...),
+		})
+	}
+
+	for _, h := range svr.hosts {
+		d.Hosts = append(d.Hosts, HostDescription{
+			Pattern: h.pattern,
+			Methods: h.svr.describeMethods(),
+		})
+	}
+
+	return d
+}
+
+// describeMethods describes the muxes of the built-in methods followed by the custom methods in the alphabetical order.
+func (svr *Server) describeMethods() []MethodDescription {
+	methods := make([]MethodDescription, 0, len(svr.muxIndices)+len(svr.customMuxes))
+	for _, idx := range svr.muxIndices {
+		methods = append(methods, describeMux(methodString(idx), svr.muxes[idx]))
+	}
+
+	custom := make([]string, 0, len(svr.customMuxes))
+	for method := range svr.customMuxes {
+		custom = append(custom, method)
+	}
+	sort.Strings(custom)
+
+	for _, method := range custom {
+		methods = append(methods, describeMux(method, svr.customMuxes[method]))
+	}
+
+	return methods
+}
+
+func describeMux(method string, mux multiplexer) MethodDescription {
+	d := MethodDescription{Method: method}
+
+	switch mux := mux.(type) {
+	case *staticMux:
+		d.Mux = "static"
+		d.Static = mux.paths()
+	case *hybridMux:
+		d.Mux = "hybrid"
+		d.Static = mux.static.paths()
+		tree := describeNode(mux.radix.tree)
+		d.Tree = &tree
+	case *radixMux:
+		d.Mux = "radix"
+		tree := describeNode(mux.tree)
+		d.Tree = &tree
+	}
+
+	return d
+}
+
+// paths returns the paths of the routes in the alphabetical order.
+func (mux *staticMux) paths() []string {
+	paths := make([]string, 0, len(mux.routes))
+	for path := range mux.routes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func describeNode(n *node) NodeDescription {
+	d := NodeDescription{Kind: "static", Prefix: n.prefix}
+	switch {
+	case n.prefix == ":":
+		d.Kind = "param"
+	case n.prefix == "*":
+		d.Kind = "wildcard"
+	}
+
+	if n.constraint != nil {
+		d.Constraint = n.constraint.expr
+	}
+
+	if n.handler != nil {
+		d.Route = n.template
+	}
+
+	for _, child := range n.children {
+		d.Children = append(d.Children, describeNode(child))
+	}
+	for _, child := range n.constrainedParams {
+		d.Children = append(d.Children, describeNode(child))
+	}
+	if n.param != nil {
+		d.Children = append(d.Children, describeNode(n.param))
+	}
+	if n.wildcard != nil {
+		d.Children = append(d.Children, describeNode(n.wildcard))
+	}
+
+	return d
+}
+
+// label returns the prefix of the node, including the constraint of the constrained param nodes. e.g.: :<int>
+// The root node, which has an empty prefix, is labelled as /.
+func (d NodeDescription) label() string {
+	switch {
+	case d.Prefix == "":
+		return "/"
+	case d.Constraint != "":
+		return d.Prefix + "<" + d.Constraint + ">"
+	}
+	return d.Prefix
+}
+
+// WriteText writes the route table and the multiplexer trees in a human-readable text format.
+// Paths stored in the map of the static and hybrid multiplexers are listed under map. e.g.:
+//
+//	GET (hybrid)
+//	map
+//	└── /users/me
+//	/
+//	├── users/
+//	│   └── :  =>  /users/:id
+//	└── files/
+//	    └── *  =>  /files/*path
+func (d ServerDescription) WriteText(w io.Writer) error {
+	var table strings.Builder
+
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHOST\tMIDDLEWARES")
+	for _, route := range d.Routes {
+		method := route.Method
+		if method == "" {
+			method = "*"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", method, route.Path, route.Name, route.Host, strings.Join(route.Middlewares, ", "))
+	}
+	_ = tw.Flush()
+
+	// Trim the padding of the empty cells at the end of the rows.
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(table.String(), "\n") {
+		if trimmed := strings.TrimRight(line, " \n"); trimmed != "" {
+			b.WriteString(trimmed)
+			b.WriteByte('\n')
+		}
+	}
+
+	writeMethodsText(&b, "", d.Methods)
+	for _, h := range d.Hosts {
+		writeMethodsText(&b, h.Pattern+" ", h.Methods)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMethodsText(b *strings.Builder, host string, methods []MethodDescription) {
+	for _, m := range methods {
+		fmt.Fprintf(b, "\n%s%s (%s)\n", host, m.Method, m.Mux)
+		if len(m.Static) > 0 {
+			b.WriteString("map\n")
+			for i, path := range m.Static {
+				if i == len(m.Static)-1 {
+					fmt.Fprintf(b, "└── %s\n", path)
+				} else {
+					fmt.Fprintf(b, "├── %s\n", path)
+				}
+			}
+		}
+		if m.Tree != nil {
+			writeNodeText(b, "", "", *m.Tree)
+		}
+	}
+}
+
+// writeNodeText writes the node on a line starting with the branch, and its children indented by the indent.
+func writeNodeText(b *strings.Builder, branch string, indent string, n NodeDescription) {
+	b.WriteString(branch)
+	b.WriteString(n.label())
+	if n.Route != "" {
+		b.WriteString("  =>  ")
+		b.WriteString(n.Route)
+	}
+	b.WriteByte('\n')
+
+	for i, child := range n.Children {
+		if i == len(n.Children)-1 {
+			writeNodeText(b, indent+"└── ", indent+"    ", child)
+		} else {
+			writeNodeText(b, indent+"├── ", indent+"│   ", child)
+		}
+	}
+}
+
+// WriteJSON writes the description as an indented JSON document.
+func (d ServerDescription) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteDOT writes the multiplexer trees as a Graphviz DOT graph, with a cluster for each method. e.g.:
+//
+//	srv.Describe().WriteDOT(f) // dot -Tsvg routes.dot -o routes.svg
+//
+// Nodes ending a route are drawn in bold and labelled with the path template of the route.
+func (d ServerDescription) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph shift {\n\trankdir=LR;\n\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	id := 0
+	writeMethodsDOT(&b, &id, "", d.Methods)
+	for _, h := range d.Hosts {
+		writeMethodsDOT(&b, &id, h.Pattern+" ", h.Methods)
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMethodsDOT(b *strings.Builder, id *int, host string, methods []MethodDescription) {
+	for _, m := range methods {
+		fmt.Fprintf(b, "\tsubgraph cluster_%d {\n\t\tlabel=%s;\n", *id, dotQuote(host+m.Method+" ("+m.Mux+")"))
+		*id++
+
+		if len(m.Static) > 0 {
+			root := *id
+			*id++
+			fmt.Fprintf(b, "\t\tn%d [label=\"map\", shape=ellipse];\n", root)
+			for _, path := range m.Static {
+				fmt.Fprintf(b, "\t\tn%d [label=%s, style=bold];\n\t\tn%d -> n%d;\n", *id, dotQuote(path), root, *id)
+				*id++
+			}
+		}
+
+		if m.Tree != nil {
+			writeNodeDOT(b, id, *m.Tree)
+		}
+
+		b.WriteString("\t}\n")
+	}
+}
+
+// writeNodeDOT writes the node and its children, and returns the id of the node.
+func writeNodeDOT(b *strings.Builder, id *int, n NodeDescription) int {
+	self := *id
+	*id++
+
+	label := n.label()
+	if n.Route != "" {
+		fmt.Fprintf(b, "\t\tn%d [label=%s, style=bold];\n", self, dotQuote(label+"\n"+n.Route))
+	} else {
+		fmt.Fprintf(b, "\t\tn%d [label=%s];\n", self, dotQuote(label))
+	}
+
+	for _, child := range n.Children {
+		fmt.Fprintf(b, "\t\tn%d -> n%d;\n", self, writeNodeDOT(b, id, child))
+	}
+
+	return self
+}
+
+// dotQuote quotes the string as a DOT ID.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// DebugHandler returns a handler which serves the description of the Server. See Server.Describe.
+// The format is selected by the format query param: text (the default), json or dot.
+//
+//	srv := router.Serve()
+//	go http.ListenAndServe("localhost:6060", shift.DebugHandler(srv))
+//
+// The description exposes the internals of the routing, therefore the handler shouldn't be served publicly.
+func DebugHandler(svr *Server) http.Handler {
+	if svr == nil {
+		panic("server cannot be nil")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := svr.Describe()
+
+		switch format := r.URL.Query().Get("format"); format {
+		case "", "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_ = d.WriteText(w)
+		case "json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = d.WriteJSON(w)
+		case "dot":
+			w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+			_ = d.WriteDOT(w)
+		default:
+			http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		}
+	})
+}
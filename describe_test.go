@@ -0,0 +1,157 @@
+package shift
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServer_Describe(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/", fakeHandler())
+	r.GET("/users/me", fakeHandler())
+	r.GET("/users/:id", fakeHandler())
+	r.GET("/users/:id<int>/posts", fakeHandler())
+	r.GET("/files/*path", fakeHandler())
+	r.POST("/users", fakeHandler())
+	r.PUT("/users/:id", fakeHandler())
+	r.Name("user.delete").DELETE("/users/:id", fakeHandler())
+	r.Host(":tenant.example.com", func(g *Group) {
+		g.GET("/dashboard", fakeHandler())
+	})
+
+	d := r.Serve().Describe()
+
+	assert(t, len(d.Routes) == 9, fmt.Sprintf("routes > expected: 9, got: %d", len(d.Routes)))
+	assert(t, d.Routes[7].Name == "user.delete", fmt.Sprintf("route name > expected: user.delete, got: %s", d.Routes[7].Name))
+	assert(t, d.Routes[8].Host == ":tenant.example.com", fmt.Sprintf("route host > expected: :tenant.example.com, got: %s", d.Routes[8].Host))
+
+	muxes := map[string]string{}
+	for _, m := range d.Methods {
+		muxes[m.Method] = m.Mux
+	}
+	expectedMuxes := map[string]string{
+		http.MethodGet:    "hybrid",
+		http.MethodPost:   "static",
+		http.MethodPut:    "radix",
+		http.MethodDelete: "radix",
+	}
+	assert(t, reflect.DeepEqual(muxes, expectedMuxes), fmt.Sprintf("muxes > expected: %v, got: %v", expectedMuxes, muxes))
+
+	get := d.Methods[0]
+	assert(t, get.Method == http.MethodGet, fmt.Sprintf("method > expected: GET, got: %s", get.Method))
+	assert(t, reflect.DeepEqual(get.Static, []string{"/", "/users/me"}), fmt.Sprintf("static > expected: [/ /users/me], got: %v", get.Static))
+
+	expectedTree := &NodeDescription{
+		Kind:   "static",
+		Prefix: "",
+		Children: []NodeDescription{
+			{Kind: "static", Prefix: "files/", Children: []NodeDescription{
+				{Kind: "wildcard", Prefix: "*", Route: "/files/*path"},
+			}},
+			{Kind: "static", Prefix: "users/", Children: []NodeDescription{
+				{Kind: "param", Prefix: ":", Constraint: "int", Children: []NodeDescription{
+					{Kind: "static", Prefix: "/posts", Route: "/users/:id<int>/posts"},
+				}},
+				{Kind: "param", Prefix: ":", Route: "/users/:id"},
+			}},
+		},
+	}
+	assert(t, reflect.DeepEqual(get.Tree, expectedTree), fmt.Sprintf("tree > expected: %+v, got: %+v", expectedTree, get.Tree))
+
+	assert(t, len(d.Hosts) == 1, fmt.Sprintf("hosts > expected: 1, got: %d", len(d.Hosts)))
+	host := d.Hosts[0]
+	assert(t, host.Pattern == ":tenant.example.com", fmt.Sprintf("host pattern > expected: :tenant.example.com, got: %s", host.Pattern))
+	assert(t, len(host.Methods) == 1 && host.Methods[0].Mux == "static", fmt.Sprintf("host muxes > expected: [GET static], got: %v", host.Methods))
+}
+
+func TestServerDescription_Write(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/users/me", fakeHandler())
+	r.GET("/users/:id", fakeHandler())
+	r.GET("/posts/:id", fakeHandler())
+	r.GET("/posts/:id/*slug", fakeHandler())
+	d := r.Serve().Describe()
+
+	t.Run("text", func(t *testing.T) {
+		b := &strings.Builder{}
+		assert(t, d.WriteText(b) == nil, "unexpected error")
+
+		expected := `METHOD  PATH              NAME  HOST  MIDDLEWARES
+GET     /users/me
+GET     /users/:id
+GET     /posts/:id
+GET     /posts/:id/*slug
+
+GET (radix)
+/
+├── posts/
+│   └── :  =>  /posts/:id
+│       └── /
+│           └── *  =>  /posts/:id/*slug
+└── users/
+    ├── me  =>  /users/me
+    └── :  =>  /users/:id
+`
+		assert(t, b.String() == expected, fmt.Sprintf("text > expected:\n%s\ngot:\n%s", expected, b.String()))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		b := &strings.Builder{}
+		assert(t, d.WriteJSON(b) == nil, "unexpected error")
+
+		var decoded ServerDescription
+		assert(t, json.Unmarshal([]byte(b.String()), &decoded) == nil, "invalid json")
+		assert(t, reflect.DeepEqual(decoded, d), fmt.Sprintf("json > expected: %+v, got: %+v", d, decoded))
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		b := &strings.Builder{}
+		assert(t, d.WriteDOT(b) == nil, "unexpected error")
+
+		dot := b.String()
+		for _, s := range []string{
+			"digraph shift {",
+			`label="GET (radix)";`,
+			`n1 [label="/"];`,
+			`[label="*\n/posts/:id/*slug", style=bold];`,
+			`[label=":\n/users/:id", style=bold];`,
+		} {
+			assert(t, strings.Contains(dot, s), fmt.Sprintf("dot > expected to contain %s, got:\n%s", s, dot))
+		}
+	})
+}
+
+func TestDebugHandler(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/users/:id", fakeHandler())
+	h := DebugHandler(r.Serve())
+
+	tt := []struct {
+		format      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{format: "", code: http.StatusOK, contentType: "text/plain; charset=utf-8", body: "GET (radix)"},
+		{format: "text", code: http.StatusOK, contentType: "text/plain; charset=utf-8", body: "GET (radix)"},
+		{format: "json", code: http.StatusOK, contentType: "application/json", body: `"mux": "radix"`},
+		{format: "dot", code: http.StatusOK, contentType: "text/vnd.graphviz; charset=utf-8", body: "digraph shift {"},
+		{format: "yaml", code: http.StatusBadRequest, contentType: "text/plain; charset=utf-8", body: "unsupported format"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.format, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/debug/routes?format="+tx.format, nil))
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("status code > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, rw.Header().Get("Content-Type") == tx.contentType, fmt.Sprintf("content type > expected: %s, got: %s", tx.contentType, rw.Header().Get("Content-Type")))
+			assert(t, strings.Contains(rw.Body.String(), tx.body), fmt.Sprintf("body > expected to contain %s, got: %s", tx.body, rw.Body.String()))
+		})
+	}
+}
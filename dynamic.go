@@ -0,0 +1,131 @@
+package shift
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// DynamicServer is an http.Handler which serves requests from a replaceable Server.
+//
+// The current Server is held behind an atomic pointer. Therefore, reading the current Server is lock-free and
+// replacing it doesn't drop in-flight requests, since they keep executing on the Server they were dispatched to.
+//
+//	router := shift.New()
+//	router.GET("/foo", FooHandler)
+//	ds := shift.NewDynamicServer(router)
+//
+//	go http.ListenAndServe(":8080", ds)
+//
+//	router.GET("/bar", BarHandler)
+//	router.Remove(http.MethodGet, "/foo")
+//	diff := ds.Reload(router) // diff.Added = GET /bar, diff.Removed = GET /foo
+//
+// The zero value replies with HTTP 503 (http.StatusServiceUnavailable) status until a Server is provided using
+// Reload or Swap.
+type DynamicServer struct {
+	current    atomic.Value // Holds *Server.
+	mu         sync.Mutex   // Serializes replacements.
+	generation uint64
+}
+
+// NewDynamicServer returns a DynamicServer serving the Server generated by the provided Router.
+func NewDynamicServer(r *Router) *DynamicServer {
+	ds := &DynamicServer{}
+	ds.current.Store(r.Serve())
+	ds.generation = 1
+	return ds
+}
+
+func (ds *DynamicServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	svr := ds.Server()
+	if svr == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	svr.ServeHTTP(w, r)
+}
+
+// Server returns the current Server. Returns <nil> if a Server hasn't been provided yet.
+func (ds *DynamicServer) Server() *Server {
+	svr, _ := ds.current.Load().(*Server)
+	return svr
+}
+
+// Generation returns the generation number of the current Server.
+// The initial Server is generation 1 and every replacement increments it by one. The zero value is generation 0.
+func (ds *DynamicServer) Generation() uint64 {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.generation
+}
+
+// Reload generates a new Server from the provided Router and replaces the current Server with it.
+// It returns the difference between the routes of the replaced Server and the new Server.
+//
+// Router is not safe for concurrent use. Make sure modifications to the Router are not performed
+// concurrently with Reload.
+func (ds *DynamicServer) Reload(r *Router) RouteDiff {
+	return ds.Swap(r.Serve())
+}
+
+// Swap replaces the current Server with the provided Server.
+// It returns the difference between the routes of the replaced Server and the provided Server.
+func (ds *DynamicServer) Swap(svr *Server) RouteDiff {
+	if svr == nil {
+		panic("server cannot be nil")
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	var prev []RouteInfo
+	if current := ds.Server(); current != nil {
+		prev = current.routes
+	}
+
+	ds.current.Store(svr)
+	ds.generation++
+
+	return DiffRoutes(prev, svr.routes)
+}
+
+// RouteDiff describes the routes added and removed between two route sets.
+type RouteDiff struct {
+	Added   []RouteInfo
+	Removed []RouteInfo
+}
+
+// Empty reports whether the route sets are equal.
+func (d RouteDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DiffRoutes returns the routes present only in next as added and the routes present only in prev as removed.
+// Routes are compared by the host, the method and the path.
+func DiffRoutes(prev, next []RouteInfo) (diff RouteDiff) {
+	type key struct{ host, method, path string }
+
+	prevSet := make(map[key]struct{}, len(prev))
+	for _, route := range prev {
+		prevSet[key{route.Host, route.Method, route.Path}] = struct{}{}
+	}
+
+	nextSet := make(map[key]struct{}, len(next))
+	for _, route := range next {
+		nextSet[key{route.Host, route.Method, route.Path}] = struct{}{}
+
+		if _, ok := prevSet[key{route.Host, route.Method, route.Path}]; !ok {
+			diff.Added = append(diff.Added, route)
+		}
+	}
+
+	for _, route := range prev {
+		if _, ok := nextSet[key{route.Host, route.Method, route.Path}]; !ok {
+			diff.Removed = append(diff.Removed, route)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,206 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDynamicServer_Reload(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/foo", fakeHandler())
+	r.GET("/bar/:id", fakeHandler())
+
+	ds := NewDynamicServer(r)
+	assert(t, ds.Generation() == 1, fmt.Sprintf("generation > expected: 1, got: %d", ds.Generation()))
+
+	serve := func(path string) int {
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		ds.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	assert(t, serve("/foo") == http.StatusOK, "expected /foo to be found")
+	assert(t, serve("/baz") == http.StatusNotFound, "expected /baz not to be found")
+
+	removed := r.Remove(http.MethodGet, "/foo")
+	assert(t, removed, "expected /foo to be removed")
+	r.POST("/baz", fakeHandler())
+	r.GET("/baz", fakeHandler())
+
+	// Modifying the router doesn't affect the current server.
+	assert(t, serve("/foo") == http.StatusOK, "expected /foo to be found before reload")
+
+	diff := ds.Reload(r)
+	assert(t, ds.Generation() == 2, fmt.Sprintf("generation > expected: 2, got: %d", ds.Generation()))
+	assert(t, serve("/foo") == http.StatusNotFound, "expected /foo not to be found after reload")
+	assert(t, serve("/baz") == http.StatusOK, "expected /baz to be found after reload")
+	assert(t, serve("/bar/1") == http.StatusOK, "expected /bar/1 to be found after reload")
+
+	assert(t, len(diff.Added) == 2, fmt.Sprintf("added routes > expected: 2, got: %d", len(diff.Added)))
+	assert(t, len(diff.Removed) == 1, fmt.Sprintf("removed routes > expected: 1, got: %d", len(diff.Removed)))
+	if len(diff.Removed) == 1 {
+		assert(t, reflect.DeepEqual(diff.Removed[0], RouteInfo{Method: http.MethodGet, Path: "/foo"}), fmt.Sprintf("removed route > expected: GET /foo, got: %v", diff.Removed[0]))
+	}
+
+	diff = ds.Reload(r)
+	assert(t, diff.Empty(), fmt.Sprintf("diff > expected: empty, got: %v", diff))
+}
+
+func TestDynamicServer_ZeroValue(t *testing.T) {
+	var ds DynamicServer
+	assert(t, ds.Server() == nil, "server > expected: <nil>")
+	assert(t, ds.Generation() == 0, fmt.Sprintf("generation > expected: 0, got: %d", ds.Generation()))
+
+	rw := httptest.NewRecorder()
+	ds.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/foo", nil))
+	assert(t, rw.Code == http.StatusServiceUnavailable, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusServiceUnavailable, rw.Code))
+
+	r := newTestRouter()
+	r.GET("/foo", fakeHandler())
+
+	diff := ds.Reload(r)
+	assert(t, ds.Generation() == 1, fmt.Sprintf("generation > expected: 1, got: %d", ds.Generation()))
+	assert(t, len(diff.Added) == 1 && len(diff.Removed) == 0, fmt.Sprintf("diff > expected: 1 added, got: %v", diff))
+
+	rw = httptest.NewRecorder()
+	ds.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/foo", nil))
+	assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+}
+
+func TestDynamicServer_InFlightRequests(t *testing.T) {
+	r := newTestRouter()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r.GET("/slow", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("old"))
+		return nil
+	})
+
+	ds := NewDynamicServer(r)
+
+	var wg sync.WaitGroup
+	rw := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		req, _ := http.NewRequest(http.MethodGet, "/slow", nil)
+		ds.ServeHTTP(rw, req)
+	}()
+
+	<-started
+	r.Remove(http.MethodGet, "/slow")
+	ds.Reload(r)
+	close(release)
+	wg.Wait()
+
+	assert(t, rw.Code == http.StatusOK, fmt.Sprintf("in-flight request http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+	assert(t, rw.Body.String() == "old", fmt.Sprintf("in-flight request body > expected: old, got: %s", rw.Body.String()))
+}
+
+func TestDynamicServer_ConfigIsolation(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/users/:id<int>", fakeHandler())
+	r.GET("/teapot", fakeHandler())
+	r.POST("/teapot", fakeHandler())
+
+	ds := NewDynamicServer(r)
+
+	serve := func(method string, path string) int {
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		ds.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					serve(http.MethodGet, "/missing")
+					serve(http.MethodGet, "/users/42")
+					serve(http.MethodHead, "/teapot")
+					serve(http.MethodPut, "/teapot")
+				}
+			}
+		}()
+	}
+
+	// Mutating the router while the server is serving requests must not race with the server (go test -race).
+	teapot := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) }
+	r.UseNotFoundHandler(teapot)
+	r.UseMethodNotAllowedHandler(nil)
+	r.UseErrorHandler(DefaultErrorHandler)
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc { return next })
+	r.UsePathDecoding(PathDecodingParams)
+	r.UseAutoHead()
+	r.UseStripHostPort()
+	r.UseTrailingSlashMatch(WithExecute())
+	r.UseParamConstraint("int", func(value string) bool { return false })
+
+	close(stop)
+	wg.Wait()
+
+	// The changes apply only once the server is reloaded.
+	assert(t, serve(http.MethodGet, "/missing") == http.StatusNotFound, "expected the not found handler of the current server")
+	assert(t, serve(http.MethodGet, "/users/42") == http.StatusOK, "expected the constraints of the current server")
+	assert(t, serve(http.MethodPut, "/teapot") == http.StatusNotFound, "expected the method not allowed handler not to be set")
+
+	ds.Reload(r)
+	assert(t, serve(http.MethodGet, "/missing") == http.StatusTeapot, "expected the not found handler of the reloaded server")
+	assert(t, serve(http.MethodGet, "/users/42") == http.StatusTeapot, "expected the constraints of the reloaded server")
+	assert(t, serve(http.MethodPut, "/teapot") == http.StatusMethodNotAllowed, "expected the method not allowed handler of the reloaded server")
+}
+
+func TestRouter_Remove(t *testing.T) {
+	r := newTestRouter()
+	r.Map([]string{http.MethodGet, http.MethodPost}, "/foo", fakeHandler())
+	r.All("/bar", fakeHandler())
+	r.Group("/v1", func(g *Group) {
+		g.GET("/baz", fakeHandler())
+	})
+
+	assert(t, r.Remove(http.MethodGet, "/foo"), "expected GET /foo to be removed")
+	assert(t, !r.Remove(http.MethodGet, "/foo"), "expected GET /foo to be already removed")
+	assert(t, !r.Remove(http.MethodGet, "/bar"), "expected GET /bar not to be removed")
+	assert(t, r.Remove("", "/bar"), "expected /bar to be removed")
+	assert(t, r.Remove(http.MethodGet, "/v1/baz"), "expected GET /v1/baz to be removed")
+
+	routes := r.Routes()
+	assert(t, len(routes) == 1, fmt.Sprintf("routes > expected: 1, got: %d", len(routes)))
+	assert(t, reflect.DeepEqual(routes[0], RouteInfo{Method: http.MethodPost, Path: "/foo"}), fmt.Sprintf("route > expected: POST /foo, got: %v", routes[0]))
+}
+
+func TestDiffRoutes(t *testing.T) {
+	prev := []RouteInfo{{Method: "GET", Path: "/a"}, {Method: "GET", Path: "/b"}, {Method: "POST", Path: "/b"}}
+	next := []RouteInfo{{Method: "GET", Path: "/b"}, {Method: "PUT", Path: "/b"}, {Method: "GET", Path: "/c"}}
+
+	diff := DiffRoutes(prev, next)
+
+	added := []RouteInfo{{Method: "PUT", Path: "/b"}, {Method: "GET", Path: "/c"}}
+	removed := []RouteInfo{{Method: "GET", Path: "/a"}, {Method: "POST", Path: "/b"}}
+
+	assert(t, len(diff.Added) == len(added), fmt.Sprintf("added > expected: %v, got: %v", added, diff.Added))
+	assert(t, len(diff.Removed) == len(removed), fmt.Sprintf("removed > expected: %v, got: %v", removed, diff.Removed))
+
+	for i := 0; i < len(added) && i < len(diff.Added); i++ {
+		assert(t, reflect.DeepEqual(diff.Added[i], added[i]), fmt.Sprintf("added > expected: %v, got: %v", added[i], diff.Added[i]))
+	}
+	for i := 0; i < len(removed) && i < len(diff.Removed); i++ {
+		assert(t, reflect.DeepEqual(diff.Removed[i], removed[i]), fmt.Sprintf("removed > expected: %v, got: %v", removed[i], diff.Removed[i]))
+	}
+}
@@ -0,0 +1,97 @@
+package shift
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorHandlerFunc handles an error returned by a HandlerFunc which reached the top of the middleware stack.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, route Route, err error)
+
+// HTTPError is an error carrying an HTTP status code, response headers and a message which is safe to be exposed
+// to the client.
+//
+// The wrapped error (Err) is never written to the response. Use it to retain the underlying cause for logging.
+type HTTPError struct {
+	Code    int
+	Header  http.Header
+	Message string
+	Err     error
+}
+
+// NewHTTPError returns an HTTPError with the provided status code and public message.
+// When message is empty, the status text of the code is used instead.
+func NewHTTPError(code int, message string) *HTTPError {
+	return &HTTPError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error returns the public message of the HTTPError.
+func (e *HTTPError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return http.StatusText(e.Code)
+}
+
+// Unwrap returns the underlying cause of the HTTPError.
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap sets the underlying cause of the HTTPError and returns it.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	e.Err = err
+	return e
+}
+
+// DefaultErrorHandler writes the HTTPError's headers, status code and public message to the response.
+// Errors that are not of type HTTPError are treated as internal errors and reply with HTTP 500 (http.StatusInternalServerError)
+// without exposing the error message to the client.
+// An HTTPError without a status code is replied with HTTP 500 as well, along with the status text if it has no message.
+func DefaultErrorHandler(w http.ResponseWriter, _ *http.Request, _ Route, err error) {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for k, values := range httpErr.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+
+	code, message := httpErr.Code, httpErr.Error()
+	if code == 0 {
+		code = http.StatusInternalServerError
+		if message == "" {
+			message = http.StatusText(code)
+		}
+	}
+
+	http.Error(w, message, code)
+}
+
+// replyError hands over the error to the router's error handler. When the route is served by a Server without an
+// error handler, the error is replied using DefaultErrorHandler instead of leaving the response empty.
+func replyError(w http.ResponseWriter, r *http.Request, route Route, err error) error {
+	if route.svr == nil || route.svr.config.errorHandler != nil {
+		return err
+	}
+
+	DefaultErrorHandler(w, r, route, err)
+	return nil
+}
+
+// errorHandlerWrapper hands over the error returned by the handler to the router's error handler.
+func errorHandlerWrapper(config *Config, handler HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, route Route) error {
+		if err := handler(w, r, route); err != nil {
+			config.errorHandler(w, r, route, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package shift
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouter_ErrorHandler(t *testing.T) {
+	r := newTestRouter()
+	r.UseTrailingSlashMatch(WithExecute())
+	r.UsePathCorrectionMatch(WithExecute())
+
+	var gotRoute string
+	var gotErr error
+	r.UseErrorHandler(func(w http.ResponseWriter, r *http.Request, route Route, err error) {
+		gotRoute = route.Path
+		gotErr = err
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	errFoo := errors.New("foo")
+	r.GET("/foo/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		return errFoo
+	})
+	r.GET("/bar", fakeHandler())
+
+	srv := r.Serve()
+
+	for _, path := range []string{"/foo/1", "/foo/1/", "/FOO/1"} {
+		t.Run(path, func(t *testing.T) {
+			gotRoute, gotErr = "", nil
+
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == http.StatusTeapot, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusTeapot, rw.Code))
+			assert(t, gotRoute == "/foo/:id", fmt.Sprintf("route > expected: /foo/:id, got: %s", gotRoute))
+			assert(t, gotErr == errFoo, fmt.Sprintf("error > expected: %v, got: %v", errFoo, gotErr))
+		})
+	}
+
+	t.Run("no error", func(t *testing.T) {
+		gotErr = nil
+
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/bar", nil)
+		srv.ServeHTTP(rw, req)
+
+		assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+		assert(t, gotErr == nil, fmt.Sprintf("error > expected: <nil>, got: %v", gotErr))
+	})
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	tt := []struct {
+		name   string
+		err    error
+		code   int
+		body   string
+		header http.Header
+	}{
+		{
+			name: "plain error",
+			err:  errors.New("database is down"),
+			code: http.StatusInternalServerError,
+			body: http.StatusText(http.StatusInternalServerError),
+		},
+		{
+			name: "http error",
+			err:  NewHTTPError(http.StatusNotFound, "order not found"),
+			code: http.StatusNotFound,
+			body: "order not found",
+		},
+		{
+			name: "http error without message",
+			err:  NewHTTPError(http.StatusConflict, ""),
+			code: http.StatusConflict,
+			body: http.StatusText(http.StatusConflict),
+		},
+		{
+			name: "http error without code",
+			err:  &HTTPError{},
+			code: http.StatusInternalServerError,
+			body: http.StatusText(http.StatusInternalServerError),
+		},
+		{
+			name: "http error without code with message",
+			err:  &HTTPError{Message: "checkout failed"},
+			code: http.StatusInternalServerError,
+			body: "checkout failed",
+		},
+		{
+			name: "wrapped http error",
+			err:  fmt.Errorf("checkout: %w", NewHTTPError(http.StatusPaymentRequired, "missing payment method").Wrap(errors.New("card declined"))),
+			code: http.StatusPaymentRequired,
+			body: "missing payment method",
+		},
+		{
+			name: "http error with headers",
+			err: &HTTPError{
+				Code:    http.StatusTooManyRequests,
+				Header:  http.Header{"Retry-After": []string{"30"}},
+				Message: "slow down",
+			},
+			code:   http.StatusTooManyRequests,
+			body:   "slow down",
+			header: http.Header{"Retry-After": []string{"30"}},
+		},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			DefaultErrorHandler(rw, req, Route{}, tx.err)
+
+			body := strings.TrimSpace(rw.Body.String())
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, body == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, body))
+			for k := range tx.header {
+				assert(t, rw.Header().Get(k) == tx.header.Get(k), fmt.Sprintf("header %s > expected: %s, got: %s", k, tx.header.Get(k), rw.Header().Get(k)))
+			}
+		})
+	}
+}
+
+func TestHTTPError_Unwrap(t *testing.T) {
+	cause := errors.New("card declined")
+	err := NewHTTPError(http.StatusPaymentRequired, "missing payment method").Wrap(cause)
+
+	assert(t, errors.Is(err, cause), "expected HTTPError to unwrap to the cause")
+	assert(t, err.Error() == "missing payment method", fmt.Sprintf("error message > expected: missing payment method, got: %s", err.Error()))
+}
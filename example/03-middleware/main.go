@@ -13,7 +13,7 @@ import (
 
 func main() {
 	r := shift.New()
-	r.Use(traceMiddleware) // Apply to all the handlers declared after Use().
+	r.Use(traceMiddleware) // Apply to all the handlers.
 
 	r.GET("/", func(w http.ResponseWriter, r *http.Request, route shift.Route) error {
 		_, err := w.Write([]byte("hello from shift"))
@@ -35,7 +35,7 @@ func main() {
 	})
 
 	r.Group("/oof", func(g *shift.Group) {
-		g.Use(authMiddleware) // Apply to all the handlers declared after Use() within this group scope.
+		g.Use(authMiddleware) // Apply to all the handlers within this group scope.
 		g.GET("/aaa", func(w http.ResponseWriter, r *http.Request, route shift.Route) error {
 			_, err := w.Write([]byte("hello from authenticated route"))
 			return err
@@ -12,14 +12,16 @@ type Group struct {
 // Use attaches middlewares to the current middleware stack.
 // The middleware stack is executed before the request handler in the order middlewares were registered.
 //
-// Make sure to register middlewares before registering routes. Otherwise, the routes registered prior to registering
-// middlewares wouldn't be executing the middlewares.
+// Middlewares apply to all the routes registered within the Group and its nested groups, including the routes
+// registered prior to calling Use. The middleware stacks are resolved when calling Router.Serve().
+// Use Router.UsePositionalMiddlewares to apply middlewares only to the routes registered after calling Use.
 //
 // Alternatively, Router.With() can be used to register middlewares for a whole group or a specific route.
 //
 // To use a net/http idiomatic middleware, wrap the middleware in the HTTPMiddlewareFunc.
 func (g *Group) Use(middlewares ...MiddlewareFunc) {
 	g.mws = append(g.mws, middlewares...)
+	g.scope.mws = append(g.scope.mws, middlewares...)
 }
 
 // Base returns the base path of the Group.
@@ -36,12 +38,12 @@ func (g *Group) Base() string {
 // Routes returns the routes registered to the Group.
 // To retrieve all the routes, use Router.Routes().
 func (g *Group) Routes() (routes []RouteInfo) {
+	config := g.scope.root().config
+	res := newMiddlewareResolver(config)
+
 	for _, log := range *g.logs {
 		if strings.HasPrefix(log.path, g.base) {
-			routes = append(routes, RouteInfo{
-				Method: log.method,
-				Path:   log.path,
-			})
+			routes = appendRouteInfo(routes, log, res, config.globalMiddlewares)
 		}
 	}
 	return
@@ -0,0 +1,144 @@
+package shift
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// hostRoutes holds the Server of the routes registered for a host pattern.
+//
+// A host pattern is a sequence of labels separated by dots. A label is either a literal or a param (:name) which matches
+// any non-empty label. e.g.: api.example.com, :tenant.example.com
+type hostRoutes struct {
+	pattern    string
+	labels     []string
+	keys       *[]string // Param keys in the reverse order of the labels.
+	paramsPool *sync.Pool
+	svr        *Server
+}
+
+func newHostRoutes(pattern string, svr *Server) *hostRoutes {
+	labels := scanHost(pattern)
+
+	var keys []string
+	for i := len(labels) - 1; i >= 0; i-- {
+		if labels[i][0] == ':' {
+			keys = append(keys, labels[i][1:])
+		}
+	}
+
+	h := &hostRoutes{
+		pattern: pattern,
+		labels:  labels,
+		svr:     svr,
+	}
+
+	if len(keys) > 0 {
+		h.keys = &keys
+		h.paramsPool = &sync.Pool{
+			New: func() any {
+				return newInternalParams(len(keys))
+			},
+		}
+	}
+
+	return h
+}
+
+// isStatic reports whether the host pattern doesn't have params.
+func (h *hostRoutes) isStatic() bool {
+	return h.keys == nil
+}
+
+// match reports whether the host matches the host pattern.
+// Labels are compared case-insensitively.
+func (h *hostRoutes) match(host string) bool {
+	i := len(h.labels) - 1
+	for end := len(host); ; {
+		if i < 0 {
+			return false
+		}
+
+		start := strings.LastIndexByte(host[:end], '.') + 1
+		label := host[start:end]
+		if label == "" {
+			return false
+		}
+
+		if pl := h.labels[i]; pl[0] != ':' && !strings.EqualFold(pl, label) {
+			return false
+		}
+		i--
+
+		if start == 0 {
+			return i < 0
+		}
+		end = start - 1
+	}
+}
+
+// params returns the host params captured from the host.
+// The host must match the host pattern. Returns <nil> if the host pattern doesn't have params.
+func (h *hostRoutes) params(host string) *internalParams {
+	if h.keys == nil {
+		return nil
+	}
+
+	ps := h.paramsPool.Get().(*internalParams)
+	ps.setKeys(h.keys)
+
+	i := len(h.labels) - 1
+	for end := len(host); i >= 0; i-- {
+		start := strings.LastIndexByte(host[:end], '.') + 1
+		if h.labels[i][0] == ':' {
+			ps.appendValue(host[start:end])
+		}
+		end = start - 1
+	}
+
+	return ps
+}
+
+func (h *hostRoutes) release(ps *internalParams) {
+	ps.reset()
+	h.paramsPool.Put(ps)
+}
+
+// scanHost validates the host pattern and splits it into labels.
+func scanHost(pattern string) []string {
+	if pattern == "" {
+		panic("host cannot be empty")
+	}
+
+	if strings.ContainsAny(pattern, "/ \t\r\n") {
+		panic(fmt.Sprintf("host %s shouldn't contain slashes or whitespace", pattern))
+	}
+
+	labels := strings.Split(pattern, ".")
+	for _, label := range labels {
+		if label == "" {
+			panic(fmt.Sprintf("host %s shouldn't contain empty labels", pattern))
+		}
+
+		if label == ":" {
+			panic(fmt.Sprintf("host %s > param must have a name", pattern))
+		}
+	}
+
+	return labels
+}
+
+// stripPort removes the port from the host if present. IPv6 hosts are supported. e.g.: [::1]:8080
+func stripPort(host string) string {
+	colon := strings.LastIndexByte(host, ':')
+	if colon == -1 {
+		return host
+	}
+
+	if bracket := strings.LastIndexByte(host, ']'); bracket > colon {
+		return host
+	}
+
+	return host[:colon]
+}
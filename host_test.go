@@ -0,0 +1,194 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouter_Host(t *testing.T) {
+	r := newTestRouter()
+
+	h := func(name string) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			_, _ = w.Write([]byte(name))
+			route.Params.ForEach(func(k, v string) {
+				_, _ = w.Write([]byte(fmt.Sprintf(" %s=%s", k, v)))
+			})
+			return nil
+		}
+	}
+
+	r.GET("/users/:id", h("default"))
+	r.Host(":tenant.example.com", func(g *Group) {
+		g.GET("/users/:id", h("tenant"))
+		g.GET("/health", h("tenant-health"))
+	})
+	r.Host("api.example.com", func(g *Group) {
+		g.GET("/users/:id", h("api"))
+		g.Group("/v2", func(g *Group) {
+			g.GET("/users", h("api-v2"))
+		})
+	})
+	r.Host(":region.:tenant.example.com", func(g *Group) {
+		g.GET("/users/:id", h("region"))
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		host string
+		path string
+		code int
+		body string
+	}{
+		{host: "example.com", path: "/users/1", code: 200, body: "default id=1"},
+		{host: "api.example.com", path: "/users/1", code: 200, body: "api id=1"},
+		{host: "API.Example.COM", path: "/users/1", code: 200, body: "api id=1"},
+		{host: "api.example.com", path: "/v2/users", code: 200, body: "api-v2"},
+		{host: "acme.example.com", path: "/users/1", code: 200, body: "tenant tenant=acme id=1"},
+		{host: "acme.example.com", path: "/health", code: 200, body: "tenant-health tenant=acme"},
+		{host: "eu.acme.example.com", path: "/users/1", code: 200, body: "region region=eu tenant=acme id=1"},
+		{host: "acme.example.com", path: "/orders", code: 404},
+		{host: "api.example.com", path: "/health", code: 404},
+		{host: "x.eu.acme.example.com", path: "/users/1", code: 200, body: "default id=1"},
+		{host: ".example.com", path: "/users/1", code: 200, body: "default id=1"},
+		{host: "acme.example.com:8080", path: "/users/1", code: 200, body: "default id=1"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.host+tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+			req.Host = tx.host
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.code == 200 {
+				assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			}
+		})
+	}
+}
+
+func TestRouter_Host_StripPort(t *testing.T) {
+	r := newTestRouter()
+	r.UseStripHostPort()
+
+	var tenant string
+	r.Host(":tenant.localhost", func(g *Group) {
+		g.GET("/", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			tenant = route.Params.Get("tenant")
+			return nil
+		})
+	})
+
+	srv := r.Serve()
+
+	for _, host := range []string{"acme.localhost:8080", "acme.localhost"} {
+		tenant = ""
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		srv.ServeHTTP(rw, req)
+
+		assert(t, rw.Code == http.StatusOK, fmt.Sprintf("%s > http status > expected: %d, got: %d", host, http.StatusOK, rw.Code))
+		assert(t, tenant == "acme", fmt.Sprintf("%s > tenant > expected: acme, got: %s", host, tenant))
+	}
+}
+
+func TestRouter_Host_Routes(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/foo", fakeHandler())
+	r.Host("api.example.com", func(g *Group) {
+		g.GET("/foo", fakeHandler())
+	})
+
+	routes := r.Routes()
+	assert(t, len(routes) == 2, fmt.Sprintf("routes > expected: 2, got: %d", len(routes)))
+	assert(t, routes[1].Host == "api.example.com", fmt.Sprintf("host > expected: api.example.com, got: %s", routes[1].Host))
+
+	assert(t, r.RemoveHost("api.example.com", http.MethodGet, "/foo"), "expected api.example.com GET /foo to be removed")
+	assert(t, len(r.Routes()) == 1, fmt.Sprintf("routes > expected: 1, got: %d", len(r.Routes())))
+}
+
+func TestServer_LookupHost(t *testing.T) {
+	r := newTestRouter()
+	r.GET("/users", fakeHandler())
+	r.Host("api.example.com", func(g *Group) {
+		g.GET("/users", fakeHandler())
+		g.GET("/users/:id", fakeHandler())
+	})
+	r.Host(":tenant.example.com", func(g *Group) {
+		g.GET("/users/:id", fakeHandler())
+	})
+	r.UseStripHostPort()
+
+	srv := r.Serve()
+
+	tt := []struct {
+		host   string
+		path   string
+		found  bool
+		params map[string]string
+	}{
+		{host: "api.example.com", path: "/users", found: true},
+		{host: "API.example.com:8080", path: "/users/42", found: true, params: map[string]string{"id": "42"}},
+		{host: "acme.example.com", path: "/users/42", found: true, params: map[string]string{"tenant": "acme", "id": "42"}},
+		{host: "acme.example.com", path: "/users", found: false},
+		{host: "example.com", path: "/users", found: true},
+		{host: "example.com", path: "/users/42", found: false},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.host+tx.path, func(t *testing.T) {
+			match, ok := srv.LookupHost(tx.host, http.MethodGet, tx.path)
+			assert(t, ok == tx.found, fmt.Sprintf("found > expected: %v, got: %v", tx.found, ok))
+
+			params := map[string]string{}
+			match.Params.ForEach(func(k, v string) {
+				params[k] = v
+			})
+			assert(t, len(params) == len(tx.params), fmt.Sprintf("params > expected: %v, got: %v", tx.params, params))
+			for k, v := range tx.params {
+				assert(t, params[k] == v, fmt.Sprintf("param %s > expected: %s, got: %s", k, v, params[k]))
+			}
+		})
+	}
+
+	_, ok := srv.Lookup(http.MethodGet, "/users/42")
+	assert(t, !ok, "expected Lookup not to find the routes of the host patterns")
+}
+
+func TestScanHost(t *testing.T) {
+	hosts := []string{
+		"",
+		"example..com",
+		".example.com",
+		"example.com/",
+		"exa mple.com",
+		":.example.com",
+	}
+
+	for _, host := range hosts {
+		rec := panicHandler(func() {
+			scanHost(host)
+		})
+		assert(t, rec != nil, fmt.Sprintf("host %s > expected to panic", host))
+	}
+}
+
+func TestStripPort(t *testing.T) {
+	tt := map[string]string{
+		"example.com":      "example.com",
+		"example.com:8080": "example.com",
+		"[::1]":            "[::1]",
+		"[::1]:8080":       "[::1]",
+	}
+
+	for host, expected := range tt {
+		actual := stripPort(host)
+		assert(t, actual == expected, fmt.Sprintf("%s > expected: %s, got: %s", host, expected, actual))
+	}
+}
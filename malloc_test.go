@@ -319,3 +319,179 @@ func TestContext_FromContext_Malloc(t *testing.T) {
 
 	assert(t, allocs == 0, fmt.Sprintf("allocations > expected: %d, got: %g", 0, allocs))
 }
+
+func TestDynamicServer_ServeHTTP_Malloc(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", fakeHandler())
+	r.GET("/users", fakeHandler())
+
+	ds := NewDynamicServer(r)
+
+	req1, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+	req2, _ := http.NewRequest(http.MethodGet, "/users", nil)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		ds.ServeHTTP(nil, req1)
+		ds.ServeHTTP(nil, req2)
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
+
+func TestRouter_Host_Malloc(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", fakeHandler())
+	r.Host(":tenant.example.com", func(g *Group) {
+		g.GET("/users/:id", fakeHandler())
+		g.GET("/users", fakeHandler())
+	})
+	r.Host("api.example.com", func(g *Group) {
+		g.GET("/users/:id", fakeHandler())
+	})
+
+	var requests []*http.Request
+	for _, tx := range []struct{ host, path string }{
+		{"example.com", "/users/42"},
+		{"acme.example.com", "/users/42"},
+		{"acme.example.com", "/users"},
+		{"api.example.com", "/users/42"},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+		req.Host = tx.host
+		requests = append(requests, req)
+	}
+
+	srv := r.Serve()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		for _, req := range requests {
+			srv.ServeHTTP(nil, req)
+		}
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
+
+func TestRouter_UseGlobal_Malloc(t *testing.T) {
+	r := New()
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			return next(w, r, route)
+		}
+	})
+	r.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {})
+	r.GET("/users/:id", fakeHandler())
+	r.GET("/users", fakeHandler())
+
+	req1, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+	req2, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	req3, _ := http.NewRequest(http.MethodGet, "/posts", nil)
+
+	srv := r.Serve()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		srv.ServeHTTP(nil, req1)
+		srv.ServeHTTP(nil, req2)
+		srv.ServeHTTP(nil, req3)
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
+
+func TestRouter_Meta_Malloc(t *testing.T) {
+	r := New()
+	r.Meta("scope", "users:read").Tags("public").Group("/users", func(g *Group) {
+		g.GET("/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			_ = route.Meta.Get("scope")
+			_ = route.Meta.HasTag("public")
+			return nil
+		})
+		g.GET("", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			_ = route.Meta.Get("scope")
+			_ = route.Meta.HasTag("public")
+			return nil
+		})
+	})
+
+	req1, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+	req2, _ := http.NewRequest(http.MethodGet, "/users", nil)
+
+	srv := r.Serve()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		srv.ServeHTTP(nil, req1)
+		srv.ServeHTTP(nil, req2)
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
+
+func TestParams_TypedAccessors_Malloc(t *testing.T) {
+	r := New()
+	r.GET("/users/:id/posts/:uuid/:flag/:date", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = route.Params.Int("id")
+		_, _ = route.Params.Int64("id")
+		_, _ = route.Params.Uint("id")
+		_, _ = route.Params.Bool("flag")
+		_, _ = route.Params.UUID("uuid")
+		_, _ = route.Params.Time("date", "2006-01-02")
+		_ = route.Params.MustInt("id")
+		_ = route.Params.MustUUID("uuid")
+		return nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/42/posts/6ba7b810-9dad-11d1-80b4-00c04fd430c8/true/2024-03-15", nil)
+
+	srv := r.Serve()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		srv.ServeHTTP(nil, req)
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
+
+func TestRouter_UsePathDecoding_Malloc(t *testing.T) {
+	r := New()
+	r.UsePathDecoding(PathDecodingParams)
+	r.GET("/users/:id/files/:name", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_ = route.Params.Get("id")
+		_ = route.Params.Get("name")
+		route.Params.ForEach(func(k, v string) {})
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42/files/readme.md", nil)
+
+	srv := r.Serve()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		srv.ServeHTTP(nil, req)
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
+
+func TestRouter_When_Malloc(t *testing.T) {
+	r := New()
+	h := func(w http.ResponseWriter, r *http.Request, route Route) error {
+		return nil
+	}
+
+	r.When(MatchHeader("X-Api-Version", "2")).GET("/users/:id", h)
+	r.When(MatchHeader("X-Api-Version", "1")).GET("/users/:id", h)
+	r.GET("/users/:id", h)
+
+	req1, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+	req1.Header.Set("X-Api-Version", "1")
+	req2, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+
+	srv := r.Serve()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		srv.ServeHTTP(nil, req1)
+		srv.ServeHTTP(nil, req2)
+	})
+
+	assert(t, allocs == 0, fmt.Sprintf("allocs > expected: 0, got: %g", allocs))
+}
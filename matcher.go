@@ -0,0 +1,251 @@
+package shift
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Matcher reports whether the request satisfies a condition of a route. See Core.When.
+type Matcher func(r *http.Request) bool
+
+// When returns an instance which registers the routes conditionally. A route registered through the instance is
+// selected only when the request satisfies all the matchers.
+//
+// Multiple routes can be registered for the same method and path with different matchers. The candidates are
+// evaluated in the registration order and the first candidate whose matchers are satisfied is executed.
+// A route registered without matchers for the same method and path acts as the fallback, therefore it must be
+// registered last.
+//
+//	router.When(shift.MatchAccept("application/vnd.api.v2+json")).GET("/users/:id", GetUserV2)
+//	router.When(shift.MatchHeader("X-Api-Version", "2")).GET("/users/:id", GetUserV2)
+//	router.GET("/users/:id", GetUser)
+//
+// When none of the candidates match, the request is replied according to Router.UseMatcherMiss.
+// Matchers are inherited by the groups created through the instance.
+func (c *Core) When(matchers ...Matcher) *Core {
+	if len(matchers) == 0 {
+		panic("matchers cannot be empty")
+	}
+
+	for _, m := range matchers {
+		if m == nil {
+			panic("matcher cannot be nil")
+		}
+	}
+
+	stack := make([]Matcher, len(c.matchers), len(c.matchers)+len(matchers))
+	copy(stack, c.matchers)
+	stack = append(stack, matchers...)
+
+	return &Core{
+		c.base,
+		c.logs,
+		c.misses,
+		c.scope,
+		c.mws,
+		c.name,
+		c.host,
+		c.meta,
+		stack,
+	}
+}
+
+// MatchHeader returns a Matcher which matches the requests having the header with the value.
+// If value is empty, it matches the requests having the header regardless of the value.
+func MatchHeader(key string, value string) Matcher {
+	key = http.CanonicalHeaderKey(key)
+	return func(r *http.Request) bool {
+		values, ok := r.Header[key]
+		if value == "" {
+			return ok
+		}
+
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchQuery returns a Matcher which matches the requests having the query param with the value.
+// If value is empty, it matches the requests having the query param regardless of the value.
+func MatchQuery(key string, value string) Matcher {
+	return func(r *http.Request) bool {
+		return hasQuery(r.URL.RawQuery, key, value)
+	}
+}
+
+// hasQuery reports whether the raw query has the key with the value, or the key with any value if value is empty.
+// The raw query is scanned directly instead of parsing it into url.Values on every check. Pairs are skipped the same
+// way as url.ParseQuery does.
+func hasQuery(query string, key string, value string) bool {
+	for query != "" {
+		var pair string
+		if i := strings.IndexByte(query, '&'); i >= 0 {
+			pair, query = query[:i], query[i+1:]
+		} else {
+			pair, query = query, ""
+		}
+
+		if pair == "" || strings.IndexByte(pair, ';') >= 0 {
+			continue
+		}
+
+		k, v := pair, ""
+		if i := strings.IndexByte(pair, '='); i >= 0 {
+			k, v = pair[:i], pair[i+1:]
+		}
+
+		if k, ok := unescapeQuery(k); !ok || k != key {
+			continue
+		}
+
+		if v, ok := unescapeQuery(v); ok && (value == "" || v == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeQuery unescapes the query component. Returns false if the component is malformed.
+func unescapeQuery(s string) (string, bool) {
+	if strings.IndexByte(s, '%') == -1 && strings.IndexByte(s, '+') == -1 {
+		return s, true
+	}
+
+	s, err := url.QueryUnescape(s)
+	return s, err == nil
+}
+
+// MatchAccept returns a Matcher which matches the requests explicitly accepting the media type in the Accept header.
+// Wildcard media ranges (e.g.: */*, application/*) and media ranges with zero quality don't match.
+func MatchAccept(mediaType string) Matcher {
+	mediaType = strings.ToLower(mediaType)
+	return func(r *http.Request) bool {
+		for _, accept := range r.Header.Values("Accept") {
+			for _, part := range strings.Split(accept, ",") {
+				mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+				if err != nil || mediaRange != mediaType {
+					continue
+				}
+
+				if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+					continue
+				}
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// UseMatcherMiss sets the status of the response when none of the routes registered for the method and the path with
+// Core.When match the request. code is either http.StatusNotFound (the default) or http.StatusNotAcceptable.
+//
+// With http.StatusNotFound, the request is replied as if the route was not registered. Thus, it's handed over to the
+// method not allowed handler when the path has been registered for other HTTP methods, otherwise to the not found handler.
+// With http.StatusNotAcceptable, the request is replied as a miss of MissNotAcceptable kind. It passes through the global
+// middlewares and the HTTPError reaches the router's error handler, or DefaultErrorHandler when an error handler isn't configured.
+func (r *Router) UseMatcherMiss(code int) {
+	if code != http.StatusNotFound && code != http.StatusNotAcceptable {
+		panic(fmt.Sprintf("matcher miss status must be %d or %d, got %d", http.StatusNotFound, http.StatusNotAcceptable, code))
+	}
+
+	r.config.matcherMissStatus = code
+}
+
+func matchAll(matchers []Matcher, r *http.Request) bool {
+	for _, m := range matchers {
+		if !m(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionalHandler executes the handler of the first candidate whose matchers are satisfied by the request.
+// handlers are the handlers of the candidates chained with the global middlewares, so that the candidate is selected
+// before executing the global middlewares and they receive the metadata of the selected candidate.
+//
+// When none of the candidates match, the request is replied as a miss.
+func conditionalHandler(candidates []routeInfo, handlers []HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, route Route) error {
+		for i, c := range candidates {
+			if matchAll(c.matchers, r) {
+				route.Meta = c.meta
+				return handlers[i](w, r, route)
+			}
+		}
+
+		svr := route.svr
+		if svr.config.matcherMissStatus == http.StatusNotAcceptable {
+			svr.serveNotAcceptable(w, r, route.Params.host)
+			return nil
+		}
+
+		svr.serveMiss(w, r, svr.requestPath(r), route.Params.host)
+		return nil
+	}
+}
+
+// mergeConditionalRoutes merges the routes registered for the same path with matchers into a single route, which
+// executes the first candidate whose matchers are satisfied by the request (see conditionalHandler). Other routes are
+// returned as is.
+func mergeConditionalRoutes(routes []routeInfo) []routeInfo {
+	conditional := map[string][]routeInfo{} // Path -> candidates.
+	for _, route := range routes {
+		if len(route.matchers) > 0 {
+			conditional[route.path] = nil
+		}
+	}
+
+	if len(conditional) == 0 {
+		return routes
+	}
+
+	merged := make([]routeInfo, 0, len(routes))
+	for _, route := range routes {
+		candidates, ok := conditional[route.path]
+		if !ok {
+			merged = append(merged, route)
+			continue
+		}
+
+		if candidates == nil {
+			// Placeholder of the merged route.
+			merged = append(merged, route)
+		}
+		conditional[route.path] = append(candidates, route)
+	}
+
+	for i, route := range merged {
+		candidates, ok := conditional[route.path]
+		if !ok {
+			continue
+		}
+
+		// Routes registered for all the methods are grouped after the others. Restore the registration order.
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].seq < candidates[j].seq
+		})
+
+		for j, c := range candidates[:len(candidates)-1] {
+			if len(c.matchers) == 0 {
+				panic(fmt.Sprintf("route %s %s without matchers shadows the %d route(s) registered after it", c.method, c.path, len(candidates)-j-1))
+			}
+		}
+
+		route.candidates = candidates
+		route.meta = candidates[0].meta
+		merged[i] = route
+	}
+
+	return merged
+}
@@ -0,0 +1,259 @@
+package shift
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCore_When(t *testing.T) {
+	r := newTestRouter()
+
+	write := func(body string) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			_, err := w.Write([]byte(body + " " + route.Params.Get("id")))
+			return err
+		}
+	}
+
+	r.When(MatchAccept("application/vnd.api.v2+json")).GET("/users/:id", write("accept v2"))
+	r.When(MatchHeader("X-Api-Version", "2")).GET("/users/:id", write("header v2"))
+	r.When(MatchQuery("format", "csv")).GET("/users/:id", write("csv"))
+	r.When(func(r *http.Request) bool { return r.URL.User != nil }).GET("/users/:id", write("user"))
+	r.GET("/users/:id", write("default"))
+
+	r.When(MatchHeader("X-Beta", "")).All("/search", write("beta"))
+	r.GET("/search", write("default"))
+
+	r.When(MatchHeader("X-Beta", ""), MatchQuery("q", "")).POST("/reports", write("beta"))
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		header map[string]string
+		code   int
+		body   string
+	}{
+		{method: http.MethodGet, path: "/users/42", header: map[string]string{"Accept": "application/json, application/vnd.api.v2+json"}, code: 200, body: "accept v2 42"},
+		{method: http.MethodGet, path: "/users/42", header: map[string]string{"Accept": "application/vnd.api.v2+json;q=0"}, code: 200, body: "default 42"},
+		{method: http.MethodGet, path: "/users/42", header: map[string]string{"Accept": "*/*"}, code: 200, body: "default 42"},
+		{method: http.MethodGet, path: "/users/42", header: map[string]string{"X-Api-Version": "2"}, code: 200, body: "header v2 42"},
+		{method: http.MethodGet, path: "/users/42", header: map[string]string{"X-Api-Version": "3"}, code: 200, body: "default 42"},
+		{method: http.MethodGet, path: "/users/42?format=csv", code: 200, body: "csv 42"},
+		{method: http.MethodGet, path: "/users/42?a=1&format=csv", code: 200, body: "csv 42"},
+		{method: http.MethodGet, path: "/users/42?%66ormat=c%73v", code: 200, body: "csv 42"},
+		{method: http.MethodGet, path: "/users/42?format=csv;x", code: 200, body: "default 42"},
+		{method: http.MethodGet, path: "/users/42?format=tsv&format=csv", code: 200, body: "csv 42"},
+		{method: http.MethodGet, path: "/users/42", code: 200, body: "default 42"},
+		{method: http.MethodGet, path: "/search", header: map[string]string{"X-Beta": "yes"}, code: 200, body: "beta "},
+		{method: http.MethodGet, path: "/search", code: 200, body: "default "},
+		{method: http.MethodPut, path: "/search", header: map[string]string{"X-Beta": "yes"}, code: 200, body: "beta "},
+		{method: http.MethodPut, path: "/search", code: 404},
+		{method: http.MethodPost, path: "/reports?q=x", header: map[string]string{"X-Beta": "yes"}, code: 200, body: "beta "},
+		{method: http.MethodPost, path: "/reports", header: map[string]string{"X-Beta": "yes"}, code: 404},
+		{method: http.MethodPost, path: "/reports?q", header: map[string]string{"X-Beta": "yes"}, code: 200, body: "beta "},
+	}
+
+	for _, tx := range tt {
+		t.Run(fmt.Sprintf("%s %s %v", tx.method, tx.path, tx.header), func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tx.method, tx.path, nil)
+			for k, v := range tx.header {
+				req.Header.Set(k, v)
+			}
+
+			srv.ServeHTTP(rw, req)
+			assert(t, rw.Code == tx.code, fmt.Sprintf("status code > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.code == 200 {
+				assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			}
+		})
+	}
+}
+
+func TestCore_When_Scope(t *testing.T) {
+	r := newTestRouter()
+	var executed []string
+
+	mw := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request, route Route) error {
+				executed = append(executed, name)
+				return next(w, r, route)
+			}
+		}
+	}
+
+	h := func(w http.ResponseWriter, r *http.Request, route Route) error {
+		executed = append(executed, route.Meta.Get("version").(string))
+		return nil
+	}
+
+	r.When(MatchHeader("X-Api-Version", "2")).Group("/v", func(g *Group) {
+		g.With(mw("mw2")).Meta("version", "2").GET("/users", h)
+	})
+	r.With(mw("mw1")).Meta("version", "1").GET("/v/users", h)
+
+	srv := r.Serve()
+
+	for version, expected := range map[string][]string{"2": {"mw2", "2"}, "": {"mw1", "1"}} {
+		executed = nil
+		req := httptest.NewRequest(http.MethodGet, "/v/users", nil)
+		req.Header.Set("X-Api-Version", version)
+		srv.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert(t, fmt.Sprint(executed) == fmt.Sprint(expected), fmt.Sprintf("version %s > expected: %v, got: %v", version, expected, executed))
+	}
+}
+
+func TestCore_When_GlobalMiddlewares(t *testing.T) {
+	r := newTestRouter()
+
+	var versions []any
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			versions = append(versions, route.Meta.Get("version"))
+			return next(w, r, route)
+		}
+	})
+
+	r.When(MatchHeader("X-Api-Version", "2")).Meta("version", "2").GET("/users", fakeHandler())
+	r.Meta("version", "1").GET("/users", fakeHandler())
+
+	srv := r.Serve()
+
+	for _, version := range []string{"2", "1"} {
+		versions = nil
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("X-Api-Version", version)
+		srv.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert(t, fmt.Sprint(versions) == "["+version+"]", fmt.Sprintf("global middleware meta > expected: [%s], got: %v", version, versions))
+	}
+}
+
+func TestCore_When_GlobalMiddlewares_NotAcceptable(t *testing.T) {
+	r := newTestRouter()
+	r.UseMatcherMiss(http.StatusNotAcceptable)
+
+	var misses []MissKind
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			misses = append(misses, route.Miss)
+			return next(w, r, route)
+		}
+	})
+
+	var handled error
+	r.UseErrorHandler(func(w http.ResponseWriter, r *http.Request, route Route, err error) {
+		handled = err
+		DefaultErrorHandler(w, r, route, err)
+	})
+
+	r.When(MatchHeader("X-Api-Version", "2")).GET("/users", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	r.Serve().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert(t, rw.Code == http.StatusNotAcceptable, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusNotAcceptable, rw.Code))
+	assert(t, fmt.Sprint(misses) == fmt.Sprint([]MissKind{MissNotAcceptable}), fmt.Sprintf("global middleware misses > expected: [%s], got: %v", MissNotAcceptable, misses))
+
+	var httpErr *HTTPError
+	assert(t, errors.As(handled, &httpErr) && httpErr.Code == http.StatusNotAcceptable, fmt.Sprintf("error handler > expected: HTTP 406, got: %v", handled))
+}
+
+func TestRouter_UseMatcherMiss(t *testing.T) {
+	for _, code := range []int{http.StatusNotFound, http.StatusNotAcceptable} {
+		r := newTestRouter()
+		r.UseMatcherMiss(code)
+		r.When(MatchAccept("application/vnd.api.v2+json")).GET("/users", fakeHandler())
+
+		rw := httptest.NewRecorder()
+		r.Serve().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users", nil))
+		assert(t, rw.Code == code, fmt.Sprintf("status code > expected: %d, got: %d", code, rw.Code))
+	}
+
+	t.Run("group not found handler", func(t *testing.T) {
+		r := newTestRouter()
+		r.Group("/api", func(g *Group) {
+			g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+			g.When(MatchHeader("X-Beta", "")).GET("/users", fakeHandler())
+		})
+
+		rw := httptest.NewRecorder()
+		r.Serve().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+		assert(t, rw.Code == http.StatusTeapot, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusTeapot, rw.Code))
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		r := newTestRouter()
+		r.UseMethodNotAllowedHandler(nil)
+		r.When(MatchHeader("X-Beta", "")).GET("/users", fakeHandler())
+		r.POST("/users", fakeHandler())
+
+		var misses []MissKind
+		r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request, route Route) error {
+				misses = append(misses, route.Miss)
+				return next(w, r, route)
+			}
+		})
+
+		rw := httptest.NewRecorder()
+		r.Serve().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users", nil))
+		assert(t, rw.Code == http.StatusMethodNotAllowed, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusMethodNotAllowed, rw.Code))
+		assert(t, rw.Header().Get("Allow") == "POST", fmt.Sprintf("allow > expected: POST, got: %s", rw.Header().Get("Allow")))
+		assert(t, fmt.Sprint(misses) == fmt.Sprint([]MissKind{MissMethodNotAllowed}), fmt.Sprintf("global middleware misses > expected: [%s], got: %v", MissMethodNotAllowed, misses))
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		pnk := panicHandler(func() {
+			newTestRouter().UseMatcherMiss(http.StatusBadRequest)
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+}
+
+func TestCore_When_Panics(t *testing.T) {
+	t.Run("empty matchers", func(t *testing.T) {
+		pnk := panicHandler(func() {
+			newTestRouter().When()
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+
+	t.Run("nil matcher", func(t *testing.T) {
+		pnk := panicHandler(func() {
+			newTestRouter().When(nil)
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+
+	t.Run("shadowed routes", func(t *testing.T) {
+		r := newTestRouter()
+		r.GET("/users", fakeHandler())
+		r.When(MatchHeader("X-Beta", "")).GET("/users", fakeHandler())
+
+		pnk := panicHandler(func() {
+			r.Serve()
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+
+	t.Run("duplicate routes without matchers", func(t *testing.T) {
+		r := newTestRouter()
+		r.GET("/users", fakeHandler())
+		r.GET("/users", fakeHandler())
+
+		pnk := panicHandler(func() {
+			r.Serve()
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+}
@@ -0,0 +1,150 @@
+package shift
+
+import "fmt"
+
+// RouteMeta holds the metadata and the tags attached to a route using Core.Meta and Core.Tags.
+// RouteMeta is immutable, so it's safe to retain beyond the request.
+//
+// Methods are safe to call on a <nil> RouteMeta, which has no metadata and tags.
+type RouteMeta struct {
+	values map[string]any
+	tags   []string
+}
+
+// Get returns the metadata value of the key. Returns <nil> if the key doesn't exist.
+func (m *RouteMeta) Get(key string) any {
+	v, _ := m.Lookup(key)
+	return v
+}
+
+// Lookup returns the metadata value of the key.
+// Returns false as the second return value if the key doesn't exist.
+func (m *RouteMeta) Lookup(key string) (any, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// HasTag reports whether the route is tagged with the tag.
+func (m *RouteMeta) HasTag(tag string) bool {
+	if m == nil {
+		return false
+	}
+
+	for _, t := range m.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags returns a copy of the tags of the route in the order they were attached.
+func (m *RouteMeta) Tags() []string {
+	if m == nil || len(m.tags) == 0 {
+		return nil
+	}
+
+	tags := make([]string, len(m.tags))
+	copy(tags, m.tags)
+	return tags
+}
+
+// Map returns a copy of the metadata of the route.
+func (m *RouteMeta) Map() map[string]any {
+	if m == nil || len(m.values) == 0 {
+		return nil
+	}
+
+	values := make(map[string]any, len(m.values))
+	for k, v := range m.values {
+		values[k] = v
+	}
+	return values
+}
+
+// with returns a copy of the RouteMeta with the key set to the value.
+func (m *RouteMeta) with(key string, value any) *RouteMeta {
+	cp := m.copy()
+	cp.values[key] = value
+	return cp
+}
+
+// withTags returns a copy of the RouteMeta with the tags appended. Duplicate tags are ignored.
+func (m *RouteMeta) withTags(tags ...string) *RouteMeta {
+	cp := m.copy()
+	for _, tag := range tags {
+		if !cp.HasTag(tag) {
+			cp.tags = append(cp.tags, tag)
+		}
+	}
+	return cp
+}
+
+func (m *RouteMeta) copy() *RouteMeta {
+	cp := &RouteMeta{values: map[string]any{}}
+	if m != nil {
+		for k, v := range m.values {
+			cp.values[k] = v
+		}
+		cp.tags = append(cp.tags, m.tags...)
+	}
+	return cp
+}
+
+// Meta returns an instance which attaches the metadata to the routes registered through it.
+// Metadata is inherited by the groups created through the instance.
+// Middlewares and request handlers can read the metadata using Route.Meta.
+//
+//	router.Meta("scope", "orders:write").POST("/orders", CreateOrder)
+//
+//	func AuthMiddleware(next shift.HandlerFunc) shift.HandlerFunc {
+//		return func(w http.ResponseWriter, r *http.Request, route shift.Route) error {
+//			scope, _ := route.Meta.Get("scope").(string)
+//			...
+//		}
+//	}
+func (c *Core) Meta(key string, value any) *Core {
+	if key == "" {
+		panic("meta key cannot be empty")
+	}
+
+	return &Core{
+		c.base,
+		c.logs,
+		c.misses,
+		c.scope,
+		c.mws,
+		c.name,
+		c.host,
+		c.meta.with(key, value),
+		c.matchers,
+	}
+}
+
+// Tags returns an instance which tags the routes registered through it.
+// Tags are inherited by the groups created through the instance.
+//
+//	router.Tags("public", "v1").GET("/status", Status)
+func (c *Core) Tags(tags ...string) *Core {
+	for _, tag := range tags {
+		if tag == "" {
+			panic(fmt.Sprintf("tags %v > tag cannot be empty", tags))
+		}
+	}
+
+	return &Core{
+		c.base,
+		c.logs,
+		c.misses,
+		c.scope,
+		c.mws,
+		c.name,
+		c.host,
+		c.meta.withTags(tags...),
+		c.matchers,
+	}
+}
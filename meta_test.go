@@ -0,0 +1,171 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCore_Meta(t *testing.T) {
+	r := New()
+	r.UseStripHostPort()
+
+	var got *RouteMeta
+	capture := func(w http.ResponseWriter, r *http.Request, route Route) error {
+		got = route.Meta
+		return nil
+	}
+
+	r.GET("/health", capture)
+	r.Meta("scope", "orders:read").Tags("public").GET("/orders", capture)
+	r.Meta("scope", "orders:write").POST("/orders/:id", capture)
+	r.Tags("internal").Group("/admin", func(g *Group) {
+		g.Meta("scope", "admin").Tags("audit", "internal").GET("/users/*path", capture)
+		g.GET("/stats", capture)
+	})
+
+	base := r.Meta("owner", "billing")
+	base.Meta("owner", "payments").GET("/payments", capture)
+	base.GET("/invoices", capture)
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		values map[string]any
+		tags   []string
+	}{
+		{method: http.MethodGet, path: "/health"},
+		{method: http.MethodGet, path: "/orders", values: map[string]any{"scope": "orders:read"}, tags: []string{"public"}},
+		{method: http.MethodPost, path: "/orders/42", values: map[string]any{"scope": "orders:write"}},
+		{method: http.MethodGet, path: "/admin/users/42/roles", values: map[string]any{"scope": "admin"}, tags: []string{"internal", "audit"}},
+		{method: http.MethodGet, path: "/admin/stats", tags: []string{"internal"}},
+		{method: http.MethodGet, path: "/payments", values: map[string]any{"owner": "payments"}},
+		{method: http.MethodGet, path: "/invoices", values: map[string]any{"owner": "billing"}},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path, func(t *testing.T) {
+			got = nil
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, reflect.DeepEqual(got.Map(), tx.values), fmt.Sprintf("meta > expected: %v, got: %v", tx.values, got.Map()))
+			assert(t, reflect.DeepEqual(got.Tags(), tx.tags), fmt.Sprintf("tags > expected: %v, got: %v", tx.tags, got.Tags()))
+			for _, tag := range tx.tags {
+				assert(t, got.HasTag(tag), fmt.Sprintf("has tag %s > expected: true, got: false", tag))
+			}
+		})
+	}
+
+	match, ok := srv.Lookup(http.MethodGet, "/orders")
+	assert(t, ok, "lookup > expected: true, got: false")
+	assert(t, match.Meta.Get("scope") == "orders:read", fmt.Sprintf("lookup meta > expected: orders:read, got: %v", match.Meta.Get("scope")))
+
+	expected := []RouteInfo{
+		{Method: http.MethodGet, Path: "/health"},
+		{Method: http.MethodGet, Path: "/orders", Meta: map[string]any{"scope": "orders:read"}, Tags: []string{"public"}},
+		{Method: http.MethodPost, Path: "/orders/:id", Meta: map[string]any{"scope": "orders:write"}},
+		{Method: http.MethodGet, Path: "/admin/users/*path", Meta: map[string]any{"scope": "admin"}, Tags: []string{"internal", "audit"}},
+		{Method: http.MethodGet, Path: "/admin/stats", Tags: []string{"internal"}},
+		{Method: http.MethodGet, Path: "/payments", Meta: map[string]any{"owner": "payments"}},
+		{Method: http.MethodGet, Path: "/invoices", Meta: map[string]any{"owner": "billing"}},
+	}
+
+	routes := r.Routes()
+	assert(t, len(routes) == len(expected), fmt.Sprintf("routes > expected: %d, got: %d", len(expected), len(routes)))
+	for i := 0; i < len(routes) && i < len(expected); i++ {
+		assert(t, reflect.DeepEqual(routes[i], expected[i]), fmt.Sprintf("route > expected: %+v, got: %+v", expected[i], routes[i]))
+	}
+}
+
+func TestCore_Meta_Middleware(t *testing.T) {
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			if scope, _ := route.Meta.Get("scope").(string); scope != "" && r.Header.Get("X-Scope") != scope {
+				w.WriteHeader(http.StatusForbidden)
+				return nil
+			}
+			return next(w, r, route)
+		}
+	})
+
+	r.GET("/orders", fakeHandler())
+	r.Meta("scope", "orders:write").POST("/orders", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		scope  string
+		code   int
+	}{
+		{method: http.MethodGet, code: http.StatusOK},
+		{method: http.MethodPost, code: http.StatusForbidden},
+		{method: http.MethodPost, scope: "orders:read", code: http.StatusForbidden},
+		{method: http.MethodPost, scope: "orders:write", code: http.StatusOK},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.scope, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, "/orders", nil)
+			req.Header.Set("X-Scope", tx.scope)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+		})
+	}
+}
+
+func TestCore_Meta_Invalid(t *testing.T) {
+	tt := []struct {
+		name string
+		f    func(r *Router)
+	}{
+		{name: "empty key", f: func(r *Router) { r.Meta("", "value") }},
+		{name: "empty tag", f: func(r *Router) { r.Tags("public", "") }},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			defer func() {
+				assert(t, recover() != nil, "expected a panic")
+			}()
+
+			tx.f(New())
+		})
+	}
+}
+
+func TestRouteMeta_Nil(t *testing.T) {
+	var m *RouteMeta
+	_, ok := m.Lookup("key")
+	assert(t, !ok, "lookup > expected: false, got: true")
+	assert(t, m.Get("key") == nil, "get > expected: <nil>")
+	assert(t, m.Tags() == nil, "tags > expected: <nil>")
+	assert(t, !m.HasTag("tag"), "has tag > expected: false, got: true")
+	assert(t, m.Map() == nil, "map > expected: <nil>")
+}
+
+func TestRouteMeta_TagsCopy(t *testing.T) {
+	r := newTestRouter()
+	r.Tags("public", "v1").GET("/status", fakeHandler())
+	svr := r.Serve()
+
+	match, _ := svr.Lookup(http.MethodGet, "/status")
+	tags := match.Meta.Tags()
+	tags[0] = "admin"
+
+	assert(t, !match.Meta.HasTag("admin"), "has tag > expected: false, got: true")
+	assert(t, reflect.DeepEqual(match.Meta.Tags(), []string{"public", "v1"}), fmt.Sprintf("tags > expected: [public v1], got: %v", match.Meta.Tags()))
+
+	d := svr.Describe()
+	d.Routes[0].Tags[0] = "admin"
+	assert(t, reflect.DeepEqual(svr.Routes()[0].Tags, []string{"public", "v1"}), fmt.Sprintf("route tags > expected: [public v1], got: %v", svr.Routes()[0].Tags))
+}
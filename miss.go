@@ -0,0 +1,176 @@
+package shift
+
+import (
+	"net/http"
+	"strings"
+)
+
+// missParam is the name of the wildcard param matching the paths under a group's base in the scope index.
+const missParam = "misspath"
+
+// missLog records a not found handler or a method not allowed handler registered for a Group.
+type missLog struct {
+	base             string
+	host             string
+	notFound         HandlerFunc
+	methodNotAllowed HandlerFunc
+	scope            *scope
+	mws              []MiddlewareFunc // Middleware stack at the time of the registration.
+}
+
+// UseNotFoundHandler registers the handler to execute when a route match is not found for a path under the Group's base.
+// The handler of the deepest Group whose base is a prefix of the requested path is executed. Requests not covered by
+// any Group fallback to the handler registered by Router.UseNotFoundHandler.
+//
+// The handler is executed through the Group's middleware stack.
+//
+//	router.Group("/api", func(g *shift.Group) {
+//		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+//			w.Header().Set("Content-Type", "application/json")
+//			w.WriteHeader(http.StatusNotFound)
+//			w.Write([]byte(`{"error":"not found"}`))
+//		})
+//	})
+func (g *Group) UseNotFoundHandler(f func(w http.ResponseWriter, r *http.Request)) {
+	if f == nil {
+		panic("handler cannot be nil")
+	}
+
+	*g.misses = append(*g.misses, missLog{
+		base: g.base,
+		host: g.host,
+		notFound: func(w http.ResponseWriter, r *http.Request, route Route) error {
+			f(w, r)
+			return nil
+		},
+		scope: g.scope,
+		mws:   g.mws[:len(g.mws):len(g.mws)],
+	})
+}
+
+// UseMethodNotAllowedHandler registers the handler to execute when a match has not been found for a path under the
+// Group's base, but the path has been registered for other HTTP methods. The handler is selected the same way as
+// Group.UseNotFoundHandler and executed through the Group's middleware stack.
+//
+// It enables the method not allowed handling for the paths under the Group's base regardless of
+// Router.UseMethodNotAllowedHandler. See Router.UseMethodNotAllowedHandler for details.
+func (g *Group) UseMethodNotAllowedHandler(f func(w http.ResponseWriter, r *http.Request, allowed []string)) {
+	if f == nil {
+		panic("handler cannot be nil")
+	}
+
+	*g.misses = append(*g.misses, missLog{
+		base: g.base,
+		host: g.host,
+		methodNotAllowed: func(w http.ResponseWriter, r *http.Request, route Route) error {
+			f(w, r, route.allowedMethods())
+			return nil
+		},
+		scope: g.scope,
+		mws:   g.mws[:len(g.mws):len(g.mws)],
+	})
+}
+
+// missHandlers holds the group-scoped not found handlers and method not allowed handlers of a Server.
+type missHandlers struct {
+	notFound         *scopeIndex
+	methodNotAllowed *scopeIndex
+}
+
+func newMissHandlers(logs []missLog, host string, config *Config, res *middlewareResolver) *missHandlers {
+	notFound, methodNotAllowed := newScopeIndex(config), newScopeIndex(config)
+
+	for _, log := range logs {
+		if log.host != host {
+			continue
+		}
+
+		mws := res.resolve(log.scope, log.mws)
+
+		if log.notFound != nil {
+			notFound.add(log.base, chain(mws, log.notFound))
+		}
+
+		if log.methodNotAllowed != nil {
+			methodNotAllowed.add(log.base, chain(mws, log.methodNotAllowed))
+		}
+	}
+
+	if notFound.empty() && methodNotAllowed.empty() {
+		return nil
+	}
+
+	return &missHandlers{notFound, methodNotAllowed}
+}
+
+// scopeIndex maps group bases to handlers. It finds the handler of the deepest group base which is a prefix of a path.
+//
+// Each base is registered on a radix tree as the base itself, the base with a trailing slash and the base followed by
+// a wildcard segment. Therefore, group bases with params are supported and the radix tree's route prioritization
+// selects the deepest base.
+type scopeIndex struct {
+	mux      *radixMux
+	handlers map[string]HandlerFunc // Group base -> handler.
+	bases    map[string]string      // Registered path template -> group base.
+	config   *Config
+}
+
+func newScopeIndex(config *Config) *scopeIndex {
+	return &scopeIndex{
+		mux:      newRadixMux(config.constraints),
+		handlers: map[string]HandlerFunc{},
+		bases:    map[string]string{},
+		config:   config,
+	}
+}
+
+// add registers the handler for the group base. A handler registered later for the same base replaces the former.
+func (idx *scopeIndex) add(base string, handler HandlerFunc) {
+	base = strings.TrimSuffix(base, "/")
+
+	if idx.config.errorHandler != nil {
+		handler = errorHandlerWrapper(idx.config, handler)
+	}
+
+	if _, ok := idx.handlers[base]; !ok {
+		templates := []string{base + "/", base + "/*" + missParam}
+		if base != "" {
+			templates = append(templates, base)
+		}
+
+		for _, template := range templates {
+			// The handler on the tree is never executed. Matched templates are resolved to handlers through the bases map.
+			idx.mux.add(template, isStatic(template), fakeMissHandler, nil)
+			idx.bases[template] = base
+		}
+	}
+
+	idx.handlers[base] = handler
+}
+
+func (idx *scopeIndex) empty() bool {
+	return len(idx.handlers) == 0
+}
+
+// find returns the handler of the deepest group base which is a prefix of the path.
+// Returns <nil> if the path is not under any group base.
+func (idx *scopeIndex) find(path string) HandlerFunc {
+	if len(idx.handlers) == 0 {
+		return nil
+	}
+
+	handler, ps, template, _ := idx.mux.find(path)
+	if handler == nil {
+		return nil
+	}
+
+	if ps != nil {
+		idx.mux.release(ps)
+	}
+
+	return idx.handlers[idx.bases[template]]
+}
+
+func fakeMissHandler(_ http.ResponseWriter, _ *http.Request, _ Route) error {
+	return nil
+}
@@ -0,0 +1,251 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGroup_UseNotFoundHandler(t *testing.T) {
+	r := New()
+	r.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("global"))
+	})
+
+	notFound := func(name string) func(w http.ResponseWriter, r *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(name))
+		}
+	}
+
+	r.Group("/api", func(g *Group) {
+		g.UseNotFoundHandler(notFound("api"))
+		g.GET("/users", fakeHandler())
+
+		g.Group("/v2", func(g *Group) {
+			g.UseNotFoundHandler(notFound("api-v2"))
+		})
+
+		g.Group("/tenants/:tenant", func(g *Group) {
+			g.UseNotFoundHandler(notFound("tenant"))
+		})
+	})
+
+	r.Group("/web/", func(g *Group) {
+		g.UseNotFoundHandler(notFound("web"))
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/api", expected: "api"},
+		{path: "/api/", expected: "api"},
+		{path: "/api/posts", expected: "api"},
+		{path: "/api/users/1", expected: "api"},
+		{path: "/api/v2", expected: "api-v2"},
+		{path: "/api/v2/", expected: "api-v2"},
+		{path: "/api/v2/users", expected: "api-v2"},
+		{path: "/api/v3/users", expected: "api"},
+		{path: "/api/tenants/acme/users", expected: "tenant"},
+		{path: "/api/tenants", expected: "api"},
+		{path: "/apiv2", expected: "global"},
+		{path: "/web", expected: "web"},
+		{path: "/web/index.html", expected: "web"},
+		{path: "/foo", expected: "global"},
+		{path: "/", expected: "global"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Body.String() == tx.expected, fmt.Sprintf("handler > expected: %s, got: %s", tx.expected, rw.Body.String()))
+		})
+	}
+}
+
+func TestGroup_UseNotFoundHandler_Root(t *testing.T) {
+	r := New()
+	r.Group("", func(g *Group) {
+		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		})
+	})
+
+	for _, path := range []string{"/", "/foo", "/foo/bar"} {
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		r.Serve().ServeHTTP(rw, req)
+		assert(t, rw.Code == http.StatusGone, fmt.Sprintf("%s > http status > expected: %d, got: %d", path, http.StatusGone, rw.Code))
+	}
+}
+
+func TestGroup_UseMethodNotAllowedHandler(t *testing.T) {
+	r := New()
+
+	var allowed []string
+	r.Group("/api", func(g *Group) {
+		g.UseMethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request, methods []string) {
+			allowed = methods
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_, _ = w.Write([]byte("api"))
+		})
+		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("api"))
+		})
+
+		g.GET("/users/:id", fakeHandler())
+		g.DELETE("/users/:id", fakeHandler())
+	})
+	r.GET("/web", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+		allow  string
+	}{
+		{method: http.MethodPut, path: "/api/users/1", code: http.StatusMethodNotAllowed, body: "api", allow: "GET, DELETE"},
+		{method: http.MethodPut, path: "/api/posts/1", code: http.StatusNotFound, body: "api"},
+		{method: http.MethodPut, path: "/web", code: http.StatusNotFound, body: "404 page not found\n"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path, func(t *testing.T) {
+			allowed = nil
+
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			assert(t, rw.Header().Get("Allow") == tx.allow, fmt.Sprintf("allow header > expected: %s, got: %s", tx.allow, rw.Header().Get("Allow")))
+			assert(t, strings.Join(allowed, ", ") == tx.allow, fmt.Sprintf("allowed > expected: %s, got: %v", tx.allow, allowed))
+		})
+	}
+}
+
+func TestGroup_MissHandlers_Middlewares(t *testing.T) {
+	r := New()
+
+	var executed []string
+	mw := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request, route Route) error {
+				executed = append(executed, name)
+				if r.Header.Get("Authorization") == "" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return nil
+				}
+				return next(w, r, route)
+			}
+		}
+	}
+
+	r.Use(mw("router"))
+	r.Group("/api", func(g *Group) {
+		g.Use(mw("api"))
+		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			executed = append(executed, "not found")
+			w.WriteHeader(http.StatusNotFound)
+		})
+		g.UseMethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request, allowed []string) {
+			executed = append(executed, "method not allowed")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		})
+		g.GET("/foo", fakeHandler())
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method   string
+		path     string
+		auth     bool
+		code     int
+		executed []string
+	}{
+		{method: http.MethodGet, path: "/api/bar", auth: true, code: http.StatusNotFound, executed: []string{"router", "api", "not found"}},
+		{method: http.MethodPost, path: "/api/foo", auth: true, code: http.StatusMethodNotAllowed, executed: []string{"router", "api", "method not allowed"}},
+		{method: http.MethodGet, path: "/api/bar", auth: false, code: http.StatusUnauthorized, executed: []string{"router"}},
+		{method: http.MethodGet, path: "/bar", auth: false, code: http.StatusNotFound, executed: nil},
+	}
+
+	for _, tx := range tt {
+		t.Run(fmt.Sprintf("%s %s auth=%v", tx.method, tx.path, tx.auth), func(t *testing.T) {
+			executed = nil
+
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, tx.path, nil)
+			if tx.auth {
+				req.Header.Set("Authorization", "token")
+			}
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, fmt.Sprint(executed) == fmt.Sprint(tx.executed), fmt.Sprintf("executed > expected: %v, got: %v", tx.executed, executed))
+		})
+	}
+}
+
+func TestGroup_UseNotFoundHandler_Host(t *testing.T) {
+	r := New()
+	r.Host("api.example.com", func(g *Group) {
+		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		g.GET("/foo", fakeHandler())
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		host string
+		code int
+	}{
+		{host: "api.example.com", code: http.StatusTeapot},
+		{host: "www.example.com", code: http.StatusNotFound},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.host, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/bar", nil)
+			req.Host = tx.host
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+		})
+	}
+}
+
+func TestRoute_Copy_Miss(t *testing.T) {
+	r := New()
+	r.UseMethodNotAllowedHandler(nil)
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			return next(w, r, route.Copy())
+		}
+	})
+	r.POST("/users", fakeHandler())
+	r.PUT("/users", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	r.Serve().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert(t, rw.Code == http.StatusMethodNotAllowed, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusMethodNotAllowed, rw.Code))
+	assert(t, rw.Header().Get("Allow") == "POST, PUT", fmt.Sprintf("allow > expected: POST, PUT, got: %s", rw.Header().Get("Allow")))
+}
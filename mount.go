@@ -0,0 +1,127 @@
+package shift
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountParam is the name of the wildcard param capturing the path after the mount prefix.
+const mountParam = "mountpath"
+
+var originalPathKey uint8
+
+// Mount attaches the http.Handler under the given prefix for all the HTTP methods.
+// The prefix is stripped from the request's URL.Path and URL.RawPath before executing the handler.
+// Use OriginalPath to retrieve the request path prior to stripping the prefix.
+//
+//	router.Mount("/debug/pprof", http.DefaultServeMux)
+//	router.Mount("/static", http.FileServer(http.Dir("./public")))
+//
+// For example, the request /static/css/main.css is served by the mounted handler as /css/main.css.
+func (c *Core) Mount(prefix string, handler http.Handler) {
+	if handler == nil {
+		panic("handler cannot be nil")
+	}
+
+	c.mount(prefix, handler, nil)
+}
+
+// MountRouter attaches the Router under the given prefix for all the HTTP methods.
+// The prefix is stripped from the request's URL.Path and URL.RawPath before executing the Router's Server.
+// The mounted routes are reported by Router.Routes() and Group.Routes() with the prefix.
+//
+// The Router is served at the time of mounting. Therefore, make sure to register the Router's routes before mounting.
+func (c *Core) MountRouter(prefix string, router *Router) {
+	if router == nil {
+		panic("router cannot be nil")
+	}
+
+	prefix = strings.TrimRight(prefix, "/")
+
+	routes := router.Routes()
+	for i := range routes {
+		routes[i].Path = c.base + prefix + routes[i].Path
+		routes[i].Name = ""
+		routes[i].Host = c.host
+	}
+
+	c.mount(prefix, router.Serve(), routes)
+}
+
+// mount registers the handler at the prefix and at the wildcard route following the prefix.
+// When routes is non-nil, the registered routes are replaced by routes in the route listings.
+func (c *Core) mount(prefix string, handler http.Handler, routes []RouteInfo) {
+	prefix = strings.TrimRight(prefix, "/")
+	h := mountHandler(handler)
+	c = &Core{c.base, c.logs, c.misses, c.scope, c.mws, "", c.host, c.meta, c.matchers} // Mounted routes are not named.
+
+	if c.base+prefix != "" {
+		// Matches the prefix itself.
+		c.Map([]string{""}, prefix, h)
+		if routes != nil {
+			(*c.logs)[len(*c.logs)-1].mounted = []RouteInfo{}
+		}
+	}
+
+	c.Map([]string{""}, prefix+"/*"+mountParam, h)
+	if routes != nil {
+		(*c.logs)[len(*c.logs)-1].mounted = routes
+	}
+}
+
+// mountHandler executes the mounted handler after stripping the mount prefix from the request's URL.
+func mountHandler(handler http.Handler) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, route Route) error {
+		// The path after the prefix is retrieved as matched, so that it's escaped when the route was matched against the escaped path.
+		ps := route.Params
+		ps.decode = false
+		handler.ServeHTTP(w, stripMountPrefix(r, ps.Get(mountParam), route.svr.config.matchesEscapedPath(r)))
+		return nil
+	}
+}
+
+// stripMountPrefix returns a shallow copy of the request whose URL path is replaced by the path after the mount prefix.
+// escaped denotes whether the router matched the route against the escaped path, in which case rest is escaped.
+func stripMountPrefix(r *http.Request, rest string, escaped bool) *http.Request {
+	ctx := r.Context()
+	if _, ok := ctx.Value(&originalPathKey).(string); !ok {
+		ctx = context.WithValue(ctx, &originalPathKey, r.URL.Path)
+	}
+
+	r2 := r.WithContext(ctx)
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+
+	if escaped {
+		rawPath := "/" + rest
+		if path, err := url.PathUnescape(rawPath); err == nil {
+			r2.URL.Path = path
+		} else {
+			r2.URL.Path = rawPath
+		}
+
+		// URL.RawPath is kept only if it differs from the default encoding of URL.Path, as url.URL does.
+		r2.URL.RawPath = ""
+		if r2.URL.EscapedPath() != rawPath {
+			r2.URL.RawPath = rawPath
+		}
+	} else {
+		r2.URL.Path = "/" + rest
+		r2.URL.RawPath = ""
+	}
+
+	return r2
+}
+
+// OriginalPath returns the request's URL.Path prior to stripping the mount prefix.
+// It returns the request's URL.Path if the request is not served by a mounted handler.
+//
+// For nested mounts, it returns the path of the outermost request.
+func OriginalPath(r *http.Request) string {
+	if path, ok := r.Context().Value(&originalPathKey).(string); ok {
+		return path
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,119 @@
+package shift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCore_Mount(t *testing.T) {
+	r := newTestRouter()
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s %s|%s|%s", r.Method, r.URL.Path, r.URL.RawPath, OriginalPath(r))
+	})
+
+	r.Mount("/legacy", echo)
+	r.Group("/v1", func(g *Group) {
+		g.Mount("/files/", echo)
+	})
+	r.GET("/legacy/new", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodGet, path: "/legacy", body: "GET /||/legacy"},
+		{method: http.MethodGet, path: "/legacy/", body: "GET /||/legacy/"},
+		{method: http.MethodPost, path: "/legacy/users/42", body: "POST /users/42||/legacy/users/42"},
+		{method: http.MethodGet, path: "/legacy/a%2Fb/c", body: "GET /a/b/c|/a%2Fb/c|/legacy/a/b/c"},
+		{method: http.MethodGet, path: "/v1/files/docs/readme.md", body: "GET /docs/readme.md||/v1/files/docs/readme.md"},
+		{method: http.MethodGet, path: "/legacy/new", body: ""},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+		})
+	}
+}
+
+func TestCore_MountRouter(t *testing.T) {
+	sub := newTestRouter()
+	sub.GET("/", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "index %s", route.Path)
+		return nil
+	})
+	sub.GET("/users/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "user %s %s %s", route.Params.Get("id"), r.URL.Path, OriginalPath(r))
+		return nil
+	})
+
+	nested := newTestRouter()
+	nested.GET("/ping", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "pong %s %s", r.URL.Path, OriginalPath(r))
+		return nil
+	})
+	sub.MountRouter("/nested", nested)
+
+	r := newTestRouter()
+	r.GET("/health", fakeHandler())
+	r.Group("/api", func(g *Group) {
+		g.MountRouter("/v2", sub)
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/api/v2", code: 200, body: "index /"},
+		{path: "/api/v2/", code: 200, body: "index /"},
+		{path: "/api/v2/users/42", code: 200, body: "user 42 /users/42 /api/v2/users/42"},
+		{path: "/api/v2/nested/ping", code: 200, body: "pong /ping /api/v2/nested/ping"},
+		{path: "/api/v2/orders", code: 404},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.code == 200 {
+				assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			}
+		})
+	}
+
+	expected := []RouteInfo{
+		{Method: http.MethodGet, Path: "/health"},
+		{Method: http.MethodGet, Path: "/api/v2/"},
+		{Method: http.MethodGet, Path: "/api/v2/users/:id"},
+		{Method: http.MethodGet, Path: "/api/v2/nested/ping"},
+	}
+
+	routes := r.Routes()
+	assert(t, len(routes) == len(expected), fmt.Sprintf("routes > expected: %v, got: %v", expected, routes))
+	for i := 0; i < len(routes) && i < len(expected); i++ {
+		assert(t, reflect.DeepEqual(routes[i], expected[i]), fmt.Sprintf("route > expected: %v, got: %v", expected[i], routes[i]))
+	}
+}
+
+func TestOriginalPath(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/foo/bar", nil)
+	assert(t, OriginalPath(req) == "/foo/bar", fmt.Sprintf("original path > expected: /foo/bar, got: %s", OriginalPath(req)))
+}
@@ -8,9 +8,13 @@ import (
 )
 
 type multiplexer interface {
-	add(path string, isStatic bool, handler HandlerFunc)
-	find(path string) (HandlerFunc, *internalParams, string)
-	findCaseInsensitive(path string, withParams bool) (h HandlerFunc, ps *internalParams, template string, matchedPath string)
+	add(path string, isStatic bool, handler HandlerFunc, meta *RouteMeta)
+	find(path string) (h HandlerFunc, ps *internalParams, template string, meta *RouteMeta)
+	findCaseInsensitive(path string, withParams bool) (h HandlerFunc, ps *internalParams, template string, meta *RouteMeta, matchedPath string)
+
+	// release puts back the internalParams object returned by find or findCaseInsensitive for reuse,
+	// when the request handler is not executed.
+	release(ps *internalParams)
 }
 
 // radixMux can store both static and param routes.
@@ -18,28 +22,30 @@ type multiplexer interface {
 //
 // It is recommended to use this multiplexer only when all the routes are param routes.
 type radixMux struct {
-	tree       *node
-	paramsPool *sync.Pool
-	maxParams  int
+	tree        *node
+	paramsPool  *sync.Pool
+	maxParams   int
+	constraints map[string]ConstraintFunc // Typed param constraints registry.
 }
 
-func newRadixMux() *radixMux {
+func newRadixMux(constraints map[string]ConstraintFunc) *radixMux {
 	return &radixMux{
-		tree:       newRootNode(),
-		paramsPool: &sync.Pool{},
-		maxParams:  0,
+		tree:        newRootNode(),
+		paramsPool:  &sync.Pool{},
+		maxParams:   0,
+		constraints: constraints,
 	}
 }
 
-func (mux *radixMux) add(path string, isStatic bool, handler HandlerFunc) {
+func (mux *radixMux) add(path string, isStatic bool, handler HandlerFunc, meta *RouteMeta) {
 	// Static routes doesn't need to worry about releasing internalParams.
 	if isStatic {
-		mux.tree.insert(path, handler)
+		mux.tree.insert(path, handler, meta, mux.constraints)
 		return
 	}
 
 	// Wrap request handler by the release params handler. So that internalParams object is put back to the pool for reuse.
-	vc := mux.tree.insert(path, releaseParamsHandler(mux.paramsPool, handler))
+	vc := mux.tree.insert(path, releaseParamsHandler(mux.paramsPool, handler), meta, mux.constraints)
 
 	if mux.paramsPool.New == nil || vc > mux.maxParams {
 		mux.maxParams = vc
@@ -57,20 +63,20 @@ func releaseParamsHandler(pool *sync.Pool, handler HandlerFunc) HandlerFunc {
 	}
 }
 
-func (mux *radixMux) find(path string) (HandlerFunc, *internalParams, string) {
+func (mux *radixMux) find(path string) (HandlerFunc, *internalParams, string, *RouteMeta) {
 	n, ps := mux.tree.search(path, func() *internalParams {
 		ps := mux.paramsPool.Get().(*internalParams)
 		return ps
 	})
 
 	if n != nil && n.handler != nil {
-		return n.handler, ps, n.template
+		return n.handler, ps, n.template, n.meta
 	}
 
-	return nil, nil, ""
+	return nil, nil, "", nil
 }
 
-func (mux *radixMux) findCaseInsensitive(path string, withParams bool) (HandlerFunc, *internalParams, string, string) {
+func (mux *radixMux) findCaseInsensitive(path string, withParams bool) (HandlerFunc, *internalParams, string, *RouteMeta, string) {
 	n, ps, matchedPath := mux.tree.caseInsensitiveSearch(path, func() *internalParams {
 		ps := mux.paramsPool.Get().(*internalParams)
 		return ps
@@ -84,10 +90,15 @@ func (mux *radixMux) findCaseInsensitive(path string, withParams bool) (HandlerF
 			ps = nil
 		}
 
-		return n.handler, ps, n.template, matchedPath
+		return n.handler, ps, n.template, n.meta, matchedPath
 	}
 
-	return nil, nil, "", ""
+	return nil, nil, "", nil, ""
+}
+
+func (mux *radixMux) release(ps *internalParams) {
+	ps.reset()
+	mux.paramsPool.Put(ps)
 }
 
 // staticMux can store only static routes.
@@ -97,18 +108,23 @@ func (mux *radixMux) findCaseInsensitive(path string, withParams bool) (HandlerF
 //
 // Only use this multiplexer only when all the routes are static routes.
 type staticMux struct {
-	routes   map[string]HandlerFunc
+	routes   map[string]staticRoute
 	byLength [][]string // route length -> route paths. Example: 4 (Length) -> /foo, /bar (Paths)
 }
 
+type staticRoute struct {
+	handler HandlerFunc
+	meta    *RouteMeta
+}
+
 func newStaticMux() *staticMux {
 	return &staticMux{
-		routes:   map[string]HandlerFunc{},
+		routes:   map[string]staticRoute{},
 		byLength: make([][]string, 0),
 	}
 }
 
-func (mux *staticMux) add(path string, isStatic bool, handler HandlerFunc) {
+func (mux *staticMux) add(path string, isStatic bool, handler HandlerFunc, meta *RouteMeta) {
 	if !isStatic {
 		return
 	}
@@ -123,27 +139,28 @@ func (mux *staticMux) add(path string, isStatic bool, handler HandlerFunc) {
 	if _, ok := mux.routes[path]; ok {
 		panic(fmt.Sprintf("route %s already registered", path))
 	}
-	mux.routes[path] = handler
+	mux.routes[path] = staticRoute{handler, meta}
 	mux.byLength[len(path)] = append(mux.byLength[len(path)], path)
 }
 
-func (mux *staticMux) find(path string) (HandlerFunc, *internalParams, string) {
+func (mux *staticMux) find(path string) (HandlerFunc, *internalParams, string, *RouteMeta) {
 	if len(path) >= len(mux.byLength) {
-		return nil, nil, ""
+		return nil, nil, "", nil
 	}
 
 	if len(mux.byLength[len(path)]) == 0 {
 		// Found no paths with the size.
-		return nil, nil, ""
+		return nil, nil, "", nil
 	}
 
 	// Lookup the routes map.
-	return mux.routes[path], nil, path
+	route := mux.routes[path]
+	return route.handler, nil, path, route.meta
 }
 
-func (mux *staticMux) findCaseInsensitive(path string, _ bool) (HandlerFunc, *internalParams, string, string) {
+func (mux *staticMux) findCaseInsensitive(path string, _ bool) (HandlerFunc, *internalParams, string, *RouteMeta, string) {
 	if len(path) >= len(mux.byLength) {
-		return nil, nil, "", ""
+		return nil, nil, "", nil, ""
 	}
 
 	// Retrieve all the paths with the provided path's length.
@@ -151,14 +168,18 @@ func (mux *staticMux) findCaseInsensitive(path string, _ bool) (HandlerFunc, *in
 		for _, key := range keys {
 			// Find a matching path.
 			if lng := longestPrefixCaseInsensitive(key, path); lng == len(path) {
-				return mux.routes[key], nil, key, key
+				route := mux.routes[key]
+				return route.handler, nil, key, route.meta, key
 			}
 		}
 	}
 
-	return nil, nil, "", ""
+	return nil, nil, "", nil, ""
 }
 
+// release is a no-op since static routes don't have params.
+func (mux *staticMux) release(_ *internalParams) {}
+
 // hybridMux can store both static and param routes.
 // It maps static routes on a staticMux and param routes on a radixMux.
 //
@@ -168,34 +189,38 @@ type hybridMux struct {
 	radix  *radixMux
 }
 
-func newHybridMux() *hybridMux {
-	return &hybridMux{newStaticMux(), newRadixMux()}
+func newHybridMux(constraints map[string]ConstraintFunc) *hybridMux {
+	return &hybridMux{newStaticMux(), newRadixMux(constraints)}
 }
 
-func (mux *hybridMux) add(path string, isStatic bool, handler HandlerFunc) {
+func (mux *hybridMux) add(path string, isStatic bool, handler HandlerFunc, meta *RouteMeta) {
 	if isStatic {
-		mux.static.add(path, isStatic, handler)
+		mux.static.add(path, isStatic, handler, meta)
 	} else {
-		mux.radix.add(path, isStatic, handler)
+		mux.radix.add(path, isStatic, handler, meta)
 	}
 }
 
-func (mux *hybridMux) find(path string) (HandlerFunc, *internalParams, string) {
-	if handler, ps, template := mux.static.find(path); handler != nil {
-		return handler, ps, template
+func (mux *hybridMux) find(path string) (HandlerFunc, *internalParams, string, *RouteMeta) {
+	if handler, ps, template, meta := mux.static.find(path); handler != nil {
+		return handler, ps, template, meta
 	}
 
 	return mux.radix.find(path)
 }
 
-func (mux *hybridMux) findCaseInsensitive(path string, withParams bool) (HandlerFunc, *internalParams, string, string) {
-	if handler, ps, template, matchedPath := mux.static.findCaseInsensitive(path, withParams); handler != nil {
-		return handler, ps, template, matchedPath
+func (mux *hybridMux) findCaseInsensitive(path string, withParams bool) (HandlerFunc, *internalParams, string, *RouteMeta, string) {
+	if handler, ps, template, meta, matchedPath := mux.static.findCaseInsensitive(path, withParams); handler != nil {
+		return handler, ps, template, meta, matchedPath
 	}
 
 	return mux.radix.findCaseInsensitive(path, withParams)
 }
 
+func (mux *hybridMux) release(ps *internalParams) {
+	mux.radix.release(ps)
+}
+
 func isStatic(path string) bool {
 	return strings.IndexFunc(path, func(r rune) bool {
 		return r == ':' || r == '*'
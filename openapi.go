@@ -0,0 +1,536 @@
+package shift
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metadata keys read by the OpenAPI document generator. Attach them to the routes using Core.Meta.
+//
+//	router.
+//		Meta(shift.OpenAPISummary, "Creates an order").
+//		Meta(shift.OpenAPIRequest, CreateOrderRequest{}).
+//		Meta(shift.OpenAPIResponse, map[int]any{http.StatusCreated: Order{}, http.StatusConflict: Problem{}}).
+//		POST("/orders", CreateOrder)
+const (
+	// OpenAPISummary is the metadata key of the operation summary. The value must be a string.
+	OpenAPISummary = "openapi.summary"
+
+	// OpenAPIDescription is the metadata key of the operation description. The value must be a string.
+	OpenAPIDescription = "openapi.description"
+
+	// OpenAPIRequest is the metadata key of the request body. The value is a value of the Go type of the JSON request body.
+	OpenAPIRequest = "openapi.request"
+
+	// OpenAPIResponse is the metadata key of the response bodies. The value is either a value of the Go type of the
+	// HTTP 200 JSON response body, or a map[int]any of status codes to values of the Go types of the JSON response bodies.
+	// A <nil> value in the map denotes a response without a body.
+	OpenAPIResponse = "openapi.response"
+)
+
+// openAPIMetaPrefix is the prefix of the metadata keys read by the OpenAPI document generator.
+// Metadata with other keys are documented under the x-shift-meta extension of the operation.
+const openAPIMetaPrefix = "openapi."
+
+// OpenAPIInfo provides the info object of the OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// OpenAPI generates an OpenAPI 3.1 JSON document from the registered routes.
+//
+// Route params (:name) and wildcard params (*name) are documented as path params ({name}) and
+// param constraints are reflected into the param schemas. Route names are used as operation ids and route tags as
+// operation tags. Request and response bodies are documented using the OpenAPIRequest and OpenAPIResponse metadata.
+//
+// Routes registered for any HTTP method (e.g.: handlers attached with Mount) are not documented.
+// When the same method and path is registered for multiple hosts, the first registered route is documented.
+func (r *Router) OpenAPI(info OpenAPIInfo) ([]byte, error) {
+	return newOpenAPIDocument(info, r.Routes(), r.config.constraints)
+}
+
+// OpenAPI generates an OpenAPI 3.1 JSON document from the routes the Server was generated with.
+// See Router.OpenAPI.
+func (svr *Server) OpenAPI(info OpenAPIInfo) ([]byte, error) {
+	return newOpenAPIDocument(info, svr.routes, svr.config.constraints)
+}
+
+// OpenAPIHandler returns a request handler which serves the OpenAPI document of the Server dispatching the request.
+// The document is generated on the first request and regenerated when the Server is replaced (see DynamicServer).
+//
+//	router.GET("/openapi.json", shift.OpenAPIHandler(shift.OpenAPIInfo{Title: "Orders API", Version: "1.0.0"}))
+func OpenAPIHandler(info OpenAPIInfo) HandlerFunc {
+	var (
+		mu  sync.Mutex
+		svr *Server
+		doc []byte
+	)
+
+	return func(w http.ResponseWriter, r *http.Request, route Route) error {
+		mu.Lock()
+		if svr != route.svr {
+			d, err := route.svr.OpenAPI(info)
+			if err != nil {
+				mu.Unlock()
+				return err
+			}
+
+			svr, doc = route.svr, d
+		}
+		d := doc
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(d)
+		return err
+	}
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components *openAPIComponents                     `json:"components,omitempty"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]schema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses,omitempty"`
+	Meta        map[string]any             `json:"x-shift-meta,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   schema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema schema `json:"schema"`
+}
+
+// schema is a JSON Schema object.
+type schema map[string]any
+
+func newOpenAPIDocument(info OpenAPIInfo, routes []RouteInfo, constraints map[string]ConstraintFunc) ([]byte, error) {
+	doc := openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    openAPIInfo(info),
+		Paths:   map[string]map[string]openAPIOperation{},
+	}
+
+	names := map[string]int{}
+	for _, route := range routes {
+		if route.Name != "" {
+			names[route.Name]++
+		}
+	}
+
+	g := newSchemaGenerator()
+	for _, route := range routes {
+		if route.Method == "" {
+			continue
+		}
+
+		// Routes with optional segments are documented under each of the path templates they match.
+		// Only the complete path template carries the operation id, to keep the operation ids unique.
+		paths := expandOptionalSegments(route.Path)
+		for i, p := range paths {
+			path, params := openAPIPath(p, constraints)
+			item := doc.Paths[path]
+			if item == nil {
+				item = map[string]openAPIOperation{}
+				doc.Paths[path] = item
+			}
+
+			method := strings.ToLower(route.Method)
+			if _, ok := item[method]; ok {
+				continue
+			}
+
+			op, err := g.operation(route, params)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", route.Method, route.Path, err)
+			}
+
+			if route.Name != "" && i == len(paths)-1 {
+				op.OperationID = route.Name
+				if names[route.Name] > 1 {
+					op.OperationID += "." + method
+				}
+			}
+
+			item[method] = op
+		}
+	}
+
+	if len(g.schemas) > 0 {
+		doc.Components = &openAPIComponents{Schemas: g.schemas}
+	}
+
+	return json.Marshal(doc)
+}
+
+// openAPIPath converts the route path into an OpenAPI path template and returns the path params.
+//
+//	e.g.:
+//	/users/:id<int>/files/*path -> /users/{id}/files/{path}
+//	/files/:name.:ext -> /files/{name}.{ext}
+func openAPIPath(path string, constraints map[string]ConstraintFunc) (string, []openAPIParameter) {
+	var params []openAPIParameter
+	var sb strings.Builder
+
+	r := newRouteScanner(path)
+	for seg := r.next(); seg != ""; seg = r.next() {
+		var name, expr string
+		switch seg[0] {
+		case ':':
+			name, expr = splitParam(seg)
+		case '*':
+			name = seg[1:]
+		default:
+			sb.WriteString(seg)
+			continue
+		}
+
+		sb.WriteString("{" + name + "}")
+		params = append(params, openAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   constraintSchema(expr, constraints),
+		})
+	}
+
+	return sb.String(), params
+}
+
+// constraintSchema returns the schema of the param values satisfying the constraint expression.
+func constraintSchema(expr string, constraints map[string]ConstraintFunc) schema {
+	if expr == "" {
+		return schema{"type": "string"}
+	}
+
+	if _, ok := constraints[expr]; ok {
+		// Custom constraints can't be described.
+		return schema{"type": "string"}
+	}
+
+	switch expr {
+	case "int":
+		return schema{"type": "integer"}
+	case "uint":
+		return schema{"type": "integer", "minimum": 0}
+	case "alpha":
+		return schema{"type": "string", "pattern": "^[A-Za-z]+$"}
+	case "alnum":
+		return schema{"type": "string", "pattern": "^[A-Za-z0-9]+$"}
+	case "uuid":
+		return schema{"type": "string", "format": "uuid"}
+	case "date":
+		return schema{"type": "string", "format": "date"}
+	default:
+		return schema{"type": "string", "pattern": "^(?:" + expr + ")$"}
+	}
+}
+
+// schemaGenerator reflects Go types into JSON Schemas.
+// Named struct types are generated into the components of the document and referenced from the other schemas.
+type schemaGenerator struct {
+	schemas map[string]schema       // Component name -> Schema.
+	names   map[reflect.Type]string // Struct type -> Component name.
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: map[string]schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// operation generates the operation object of the route.
+func (g *schemaGenerator) operation(route RouteInfo, params []openAPIParameter) (op openAPIOperation, err error) {
+	op.Tags = route.Tags
+	op.Parameters = params
+
+	for key, value := range route.Meta {
+		switch key {
+		case OpenAPISummary:
+			op.Summary, _ = value.(string)
+		case OpenAPIDescription:
+			op.Description, _ = value.(string)
+		case OpenAPIRequest:
+			if value != nil {
+				op.RequestBody = &openAPIRequestBody{
+					Required: true,
+					Content:  jsonContent(g.schemaOf(reflect.TypeOf(value))),
+				}
+			}
+		case OpenAPIResponse:
+			if op.Responses, err = g.responses(value); err != nil {
+				return
+			}
+		default:
+			if strings.HasPrefix(key, openAPIMetaPrefix) {
+				continue
+			}
+
+			// Metadata which can't be represented in JSON is not documented.
+			if _, err := json.Marshal(value); err != nil {
+				continue
+			}
+
+			if op.Meta == nil {
+				op.Meta = map[string]any{}
+			}
+			op.Meta[key] = value
+		}
+	}
+
+	return
+}
+
+func (g *schemaGenerator) responses(value any) (map[string]openAPIResponse, error) {
+	bodies, ok := value.(map[int]any)
+	if !ok {
+		bodies = map[int]any{http.StatusOK: value}
+	}
+
+	responses := make(map[string]openAPIResponse, len(bodies))
+	for code, body := range bodies {
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid response status code %d", code)
+		}
+
+		res := openAPIResponse{Description: http.StatusText(code)}
+		if res.Description == "" {
+			res.Description = strconv.Itoa(code)
+		}
+
+		if body != nil {
+			res.Content = jsonContent(g.schemaOf(reflect.TypeOf(body)))
+		}
+		responses[strconv.Itoa(code)] = res
+	}
+
+	return responses, nil
+}
+
+func jsonContent(s schema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: s}}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaOf returns the schema of the JSON encoding of the type as produced by the encoding/json package.
+func (g *schemaGenerator) schemaOf(t reflect.Type) schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return schema{"type": "string", "format": "date-time"}
+	case implements(t, jsonMarshalerType):
+		// The encoding is up to the type.
+		return schema{}
+	case implements(t, textMarshalerType):
+		return schema{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16:
+		return schema{"type": "integer"}
+	case reflect.Int32:
+		return schema{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return schema{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return schema{"type": "integer", "minimum": 0}
+	case reflect.Float32:
+		return schema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return schema{"type": "number", "format": "double"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), jsonMarshalerType) && !implements(t.Elem(), textMarshalerType) {
+			// []byte is encoded as a base64 string.
+			return schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return schema{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Array:
+		return schema{"type": "array", "items": g.schemaOf(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return schema{"$ref": "#/components/schemas/" + g.component(t)}
+	default:
+		// Interfaces can hold any value. Channels, funcs and complex numbers can't be encoded.
+		return schema{}
+	}
+}
+
+// component generates the schema of the named struct type into the components and returns the component name.
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := componentName(t.Name())
+	if _, ok := g.schemas[name]; ok {
+		// Another type with the same name has been generated. Qualify with the package name.
+		pkg := t.PkgPath()
+		name = componentName(pkg[strings.LastIndexByte(pkg, '/')+1:] + "." + t.Name())
+		for i := 2; ; i++ {
+			if _, ok := g.schemas[name]; !ok {
+				break
+			}
+			name = componentName(fmt.Sprintf("%s.%s%d", pkg[strings.LastIndexByte(pkg, '/')+1:], t.Name(), i))
+		}
+	}
+
+	// Register before generating the schema, so that recursive types reference the component.
+	g.names[t] = name
+	g.schemas[name] = nil
+	g.schemas[name] = g.structSchema(t)
+	return name
+}
+
+var componentNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// componentName replaces the characters not allowed in component names. e.g.: Page[main.Order] -> Page_main.Order_
+func componentName(name string) string {
+	return componentNameReplacer.ReplaceAllString(name, "_")
+}
+
+// structSchema returns the object schema of the struct type following the field rules of the encoding/json package.
+func (g *schemaGenerator) structSchema(t reflect.Type) schema {
+	properties := map[string]schema{}
+	var required []string
+	g.addFields(t, properties, &required, map[reflect.Type]bool{})
+
+	s := schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// addFields adds the fields of the struct type to the properties. Fields of embedded structs are promoted.
+// Properties added first take precedence over the properties with the same name.
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]schema, required *[]string, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous {
+			if name == "" && ft.Kind() == reflect.Struct {
+				g.addFields(ft, properties, required, visited)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if _, ok := properties[name]; ok {
+			continue
+		}
+
+		s := g.schemaOf(field.Type)
+		if hasOption(opts, "string") {
+			switch ft.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64, reflect.String:
+				s = schema{"type": "string"}
+			}
+		}
+
+		properties[name] = s
+		if !hasOption(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func hasOption(opts string, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
@@ -0,0 +1,213 @@
+package shift
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type openAPITestOrder struct {
+	ID        int               `json:"id"`
+	Items     []openAPITestItem `json:"items"`
+	Note      *string           `json:"note,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Parent    *openAPITestOrder `json:"parent,omitempty"`
+	Total     int64             `json:"total,string"`
+	Secret    string            `json:"-"`
+	internal  string            //nolint:unused
+	openAPITestAudit
+}
+
+type openAPITestItem struct {
+	SKU      string
+	Quantity uint `json:"quantity"`
+}
+
+type openAPITestAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+func TestRouter_OpenAPI(t *testing.T) {
+	r := New()
+	r.Name("orders.list").Tags("orders").Meta(OpenAPISummary, "Lists orders").Meta(OpenAPIResponse, []openAPITestOrder{}).GET("/orders", fakeHandler())
+	r.Tags("orders").
+		Meta(OpenAPIRequest, openAPITestItem{}).
+		Meta(OpenAPIResponse, map[int]any{http.StatusCreated: openAPITestOrder{}, http.StatusNoContent: nil}).
+		Meta("scope", "orders:write").
+		POST("/orders/:id<int>/items", fakeHandler())
+	r.Name("files").Map([]string{http.MethodGet, http.MethodPut}, "/files/:bucket<[a-z]+>/*path", fakeHandler())
+	r.Mount("/legacy", http.NotFoundHandler())
+
+	b, err := r.OpenAPI(OpenAPIInfo{Title: "Orders", Version: "1.0.0"})
+	assert(t, err == nil, fmt.Sprintf("error > expected: <nil>, got: %v", err))
+
+	var doc map[string]any
+	assert(t, json.Unmarshal(b, &doc) == nil, "document > expected: valid json")
+
+	expected := map[string]any{}
+	assert(t, json.Unmarshal([]byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Orders", "version": "1.0.0"},
+		"paths": {
+			"/orders": {
+				"get": {
+					"operationId": "orders.list",
+					"summary": "Lists orders",
+					"tags": ["orders"],
+					"responses": {
+						"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/openAPITestOrder"}}}}}
+					}
+				}
+			},
+			"/orders/{id}/items": {
+				"post": {
+					"tags": ["orders"],
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/openAPITestItem"}}}},
+					"responses": {
+						"201": {"description": "Created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/openAPITestOrder"}}}},
+						"204": {"description": "No Content"}
+					},
+					"x-shift-meta": {"scope": "orders:write"}
+				}
+			},
+			"/files/{bucket}/{path}": {
+				"get": {
+					"operationId": "files.get",
+					"parameters": [
+						{"name": "bucket", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^(?:[a-z]+)$"}},
+						{"name": "path", "in": "path", "required": true, "schema": {"type": "string"}}
+					]
+				},
+				"put": {
+					"operationId": "files.put",
+					"parameters": [
+						{"name": "bucket", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^(?:[a-z]+)$"}},
+						{"name": "path", "in": "path", "required": true, "schema": {"type": "string"}}
+					]
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"openAPITestOrder": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "integer"},
+						"items": {"type": "array", "items": {"$ref": "#/components/schemas/openAPITestItem"}},
+						"note": {"type": "string"},
+						"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+						"created_at": {"type": "string", "format": "date-time"},
+						"parent": {"$ref": "#/components/schemas/openAPITestOrder"},
+						"total": {"type": "string"},
+						"created_by": {"type": "string"}
+					},
+					"required": ["id", "items", "created_at", "total", "created_by"]
+				},
+				"openAPITestItem": {
+					"type": "object",
+					"properties": {
+						"SKU": {"type": "string"},
+						"quantity": {"type": "integer", "minimum": 0}
+					},
+					"required": ["SKU", "quantity"]
+				}
+			}
+		}
+	}`), &expected) == nil, "expected document > expected: valid json")
+
+	assert(t, reflect.DeepEqual(doc, expected), fmt.Sprintf("document > expected: %v, got: %s", expected, b))
+}
+
+func TestRouter_OpenAPI_PathTemplates(t *testing.T) {
+	r := New()
+	r.Name("download").GET("/files/:name.:ext<[a-z]+>", fakeHandler())
+	r.Name("archive").GET("/archive/:year<int>/:month<int>?", fakeHandler())
+
+	b, err := r.OpenAPI(OpenAPIInfo{Title: "Files", Version: "1.0.0"})
+	assert(t, err == nil, fmt.Sprintf("error > expected: <nil>, got: %v", err))
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name string `json:"name"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	assert(t, json.Unmarshal(b, &doc) == nil, "document > expected: valid json")
+
+	expected := map[string]struct {
+		operationID string
+		params      []string
+	}{
+		"/files/{name}.{ext}":     {operationID: "download", params: []string{"name", "ext"}},
+		"/archive/{year}":         {params: []string{"year"}},
+		"/archive/{year}/{month}": {operationID: "archive", params: []string{"year", "month"}},
+	}
+	assert(t, len(doc.Paths) == len(expected), fmt.Sprintf("paths count > expected: %d, got: %d", len(expected), len(doc.Paths)))
+
+	for path, e := range expected {
+		op, ok := doc.Paths[path]["get"]
+		assert(t, ok, fmt.Sprintf("%s > expected a get operation", path))
+		assert(t, op.OperationID == e.operationID, fmt.Sprintf("%s operation id > expected: %s, got: %s", path, e.operationID, op.OperationID))
+
+		var params []string
+		for _, p := range op.Parameters {
+			params = append(params, p.Name)
+		}
+		assert(t, reflect.DeepEqual(params, e.params), fmt.Sprintf("%s params > expected: %v, got: %v", path, e.params, params))
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	r := New()
+	r.GET("/openapi.json", OpenAPIHandler(OpenAPIInfo{Title: "API", Version: "1"}))
+	r.Host("api.example.com", func(g *Group) {
+		g.GET("/openapi.json", OpenAPIHandler(OpenAPIInfo{Title: "API", Version: "1"}))
+		g.GET("/users/:id", fakeHandler())
+	})
+
+	ds := NewDynamicServer(r)
+
+	paths := func(host string) map[string]any {
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+		req.Host = host
+		ds.ServeHTTP(rw, req)
+
+		assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: 200, got: %d", rw.Code))
+		assert(t, rw.Header().Get("Content-Type") == "application/json", fmt.Sprintf("content type > expected: application/json, got: %s", rw.Header().Get("Content-Type")))
+
+		var doc struct {
+			Paths map[string]any `json:"paths"`
+		}
+		assert(t, json.Unmarshal(rw.Body.Bytes(), &doc) == nil, "document > expected: valid json")
+		return doc.Paths
+	}
+
+	got := paths("example.com")
+	assert(t, len(got) == 2 && got["/openapi.json"] != nil && got["/users/{id}"] != nil, fmt.Sprintf("paths > got: %v", got))
+
+	got = paths("api.example.com")
+	assert(t, len(got) == 2, fmt.Sprintf("paths > got: %v", got))
+
+	r.GET("/orders", fakeHandler())
+	ds.Reload(r)
+
+	got = paths("example.com")
+	assert(t, len(got) == 3 && got["/orders"] != nil, fmt.Sprintf("paths > got: %v", got))
+}
+
+func TestRouter_OpenAPI_InvalidResponse(t *testing.T) {
+	r := New()
+	r.Meta(OpenAPIResponse, map[int]any{42: nil}).GET("/foo", fakeHandler())
+
+	_, err := r.OpenAPI(OpenAPIInfo{})
+	assert(t, err != nil, "error > expected: non-nil, got: <nil>")
+}
@@ -1,6 +1,14 @@
 package shift
 
-import "sync"
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
 
 // Param is a key-value pair of request's route params.
 type Param struct {
@@ -18,6 +26,8 @@ type Param struct {
 // which can potentially be used by another request.
 type Params struct {
 	internal *internalParams
+	host     *internalParams // Params captured from the host pattern. See Router.Host.
+	decode   bool            // Denotes whether the path param values are percent-decoded when accessed. See Router.UsePathDecoding.
 }
 
 func newParams(internalParams *internalParams) Params {
@@ -27,56 +37,93 @@ func newParams(internalParams *internalParams) Params {
 }
 
 // Get retrieves the value associated with the provided key.
+// Path params take precedence over host params with the same key.
 func (p *Params) Get(key string) string {
-	if p.internal == nil {
-		return ""
-	}
-	return p.internal.get(key)
+	v, _ := p.Lookup(key)
+	return v
 }
 
 // ForEach iterates through Params in the order params are defined in the route.
+// Host params are iterated before path params.
 func (p *Params) ForEach(fn func(k, v string)) {
-	if p.internal == nil {
-		return
+	if p.host != nil {
+		p.host.forEach(fn)
+	}
+	if p.internal != nil {
+		if p.decode {
+			p.internal.forEach(func(k, v string) {
+				fn(k, unescapeParam(v))
+			})
+			return
+		}
+		p.internal.forEach(fn)
 	}
-	p.internal.forEach(fn)
 }
 
 // Map returns Params mapped into a [key]value map.
 func (p *Params) Map() map[string]string {
-	if p.internal == nil {
-		return nil
+	if p.host == nil && (p.internal == nil || !p.decode) {
+		if p.internal == nil {
+			return nil
+		}
+		return p.internal.kvMap()
 	}
-	return p.internal.kvMap()
+
+	// Path params are iterated after host params. Thus, path params override host params with the same key.
+	params := make(map[string]string, p.Len())
+	p.ForEach(func(k, v string) {
+		params[k] = v
+	})
+	return params
 }
 
 // Slice returns a slice of Param in the order params are defined in the route.
+// Host params are placed before path params.
 func (p *Params) Slice() []Param {
-	if p.internal == nil {
-		return nil
+	if p.host == nil && (p.internal == nil || !p.decode) {
+		if p.internal == nil {
+			return nil
+		}
+		return p.internal.slice()
 	}
-	return p.internal.slice()
+
+	params := make([]Param, 0, p.Len())
+	p.ForEach(func(k, v string) {
+		params = append(params, Param{Key: k, Value: v})
+	})
+	return params
 }
 
 // Len returns the length of Params.
 func (p *Params) Len() int {
-	if p.internal == nil {
-		return 0
+	l := 0
+	if p.internal != nil {
+		l += len(p.internal.values)
 	}
-	return len(p.internal.values)
+	if p.host != nil {
+		l += len(p.host.values)
+	}
+	return l
 }
 
 // Copy returns a deep-copy of Params.
 func (p *Params) Copy() Params {
-	if p.internal == nil {
-		return *p
+	cp := *p
+	if p.internal != nil {
+		cp.internal = p.internal.deepCopy()
 	}
-	return Params{
-		internal: p.internal.deepCopy(),
+	if p.host != nil {
+		cp.host = p.host.deepCopy()
 	}
+	return cp
 }
 
 func (p *Params) release(pool *sync.Pool) {
+	// Routes with optional segments may match without any params.
+	if p.internal == nil {
+		return
+	}
+
 	p.internal.reset()
 	pool.Put(p.internal)
 	p.internal = nil
@@ -124,14 +171,21 @@ func (p *internalParams) reset() {
 
 // get retrieves the value associated with the provided key.
 func (p *internalParams) get(key string) string {
+	v, _ := p.lookup(key)
+	return v
+}
+
+// lookup retrieves the value associated with the provided key.
+// Returns false as the second return value if the key doesn't exist.
+func (p *internalParams) lookup(key string) (string, bool) {
 	if p.keys != nil {
 		for i, k := range *p.keys {
 			if k == key {
-				return p.values[i]
+				return p.values[i], true
 			}
 		}
 	}
-	return ""
+	return "", false
 }
 
 // forEach iterates through internalParams in the order params are defined in the route.
@@ -180,3 +234,253 @@ func (p *internalParams) deepCopy() *internalParams {
 		values: values,
 	}
 }
+
+// Lookup retrieves the value associated with the provided key.
+// Returns false as the second return value if the key doesn't exist.
+// Path params take precedence over host params with the same key.
+func (p *Params) Lookup(key string) (string, bool) {
+	if p.internal != nil {
+		if v, ok := p.internal.lookup(key); ok {
+			if p.decode {
+				return unescapeParam(v), true
+			}
+			return v, true
+		}
+	}
+	if p.host != nil {
+		return p.host.lookup(key)
+	}
+	return "", false
+}
+
+// ParamError describes a failure to retrieve a typed param value.
+// Err is ErrMissingParam if the param doesn't exist, otherwise the parsing error.
+type ParamError struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	if e.Err == ErrMissingParam {
+		return fmt.Sprintf("param %s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("param %s: invalid value %q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// ErrInvalidUUID is returned within a ParamError when the param value is not a UUID.
+var ErrInvalidUUID = errors.New("invalid uuid")
+
+// UUID is a UUID parsed from the canonical 8-4-4-4-12 hex form.
+type UUID [16]byte
+
+// String returns the UUID in the canonical 8-4-4-4-12 lowercase hex form.
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// Int retrieves the value associated with the provided key as an int.
+func (p *Params) Int(key string) (int, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return 0, &ParamError{key, "", ErrMissingParam}
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &ParamError{key, v, err}
+	}
+	return n, nil
+}
+
+// Int64 retrieves the value associated with the provided key as an int64.
+func (p *Params) Int64(key string) (int64, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return 0, &ParamError{key, "", ErrMissingParam}
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, &ParamError{key, v, err}
+	}
+	return n, nil
+}
+
+// Uint retrieves the value associated with the provided key as a uint.
+func (p *Params) Uint(key string) (uint, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return 0, &ParamError{key, "", ErrMissingParam}
+	}
+
+	n, err := strconv.ParseUint(v, 10, 0)
+	if err != nil {
+		return 0, &ParamError{key, v, err}
+	}
+	return uint(n), nil
+}
+
+// Bool retrieves the value associated with the provided key as a bool.
+// It accepts the values accepted by strconv.ParseBool.
+func (p *Params) Bool(key string) (bool, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return false, &ParamError{key, "", ErrMissingParam}
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, &ParamError{key, v, err}
+	}
+	return b, nil
+}
+
+// UUID retrieves the value associated with the provided key as a UUID.
+// The value must be in the canonical 8-4-4-4-12 hex form.
+func (p *Params) UUID(key string) (UUID, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return UUID{}, &ParamError{key, "", ErrMissingParam}
+	}
+
+	if !isUUID(v) {
+		return UUID{}, &ParamError{key, v, ErrInvalidUUID}
+	}
+
+	var u UUID
+	j := 0
+	for i := 0; i < len(v); i += 2 {
+		if v[i] == '-' {
+			i++
+		}
+		u[j] = unhex(v[i])<<4 | unhex(v[i+1])
+		j++
+	}
+	return u, nil
+}
+
+// Time retrieves the value associated with the provided key as a time.Time parsed with the provided layout.
+// See time.Parse.
+func (p *Params) Time(key string, layout string) (time.Time, error) {
+	v, ok := p.Lookup(key)
+	if !ok {
+		return time.Time{}, &ParamError{key, "", ErrMissingParam}
+	}
+
+	t, err := time.Parse(layout, v)
+	if err != nil {
+		return time.Time{}, &ParamError{key, v, err}
+	}
+	return t, nil
+}
+
+// MustInt is same as Int, except it aborts the request handler with HTTP 400 (http.StatusBadRequest) status
+// when the value is missing or invalid.
+//
+// The MustX accessors are meant to be used within the request handler. The HTTPError wrapping the ParamError passes
+// through the middleware stack and reaches the router's error handler (see Router.UseErrorHandler), as if it was
+// returned by the request handler. It's replied using DefaultErrorHandler when an error handler isn't configured.
+func (p *Params) MustInt(key string) int {
+	n, err := p.Int(key)
+	p.must(err)
+	return n
+}
+
+// MustInt64 is same as Int64, except it aborts the request handler with HTTP 400 (http.StatusBadRequest) status
+// when the value is missing or invalid.
+func (p *Params) MustInt64(key string) int64 {
+	n, err := p.Int64(key)
+	p.must(err)
+	return n
+}
+
+// MustUint is same as Uint, except it aborts the request handler with HTTP 400 (http.StatusBadRequest) status
+// when the value is missing or invalid.
+func (p *Params) MustUint(key string) uint {
+	n, err := p.Uint(key)
+	p.must(err)
+	return n
+}
+
+// MustBool is same as Bool, except it aborts the request handler with HTTP 400 (http.StatusBadRequest) status
+// when the value is missing or invalid.
+func (p *Params) MustBool(key string) bool {
+	b, err := p.Bool(key)
+	p.must(err)
+	return b
+}
+
+// MustUUID is same as UUID, except it aborts the request handler with HTTP 400 (http.StatusBadRequest) status
+// when the value is missing or invalid.
+func (p *Params) MustUUID(key string) UUID {
+	u, err := p.UUID(key)
+	p.must(err)
+	return u
+}
+
+// MustTime is same as Time, except it aborts the request handler with HTTP 400 (http.StatusBadRequest) status
+// when the value is missing or invalid.
+func (p *Params) MustTime(key string, layout string) time.Time {
+	t, err := p.Time(key, layout)
+	p.must(err)
+	return t
+}
+
+// mustParamPanic is the panic value of the MustX accessors of Params.
+type mustParamPanic struct {
+	err error
+}
+
+// must aborts the request handler if the error is not <nil>. See recoverMustParam.
+func (p *Params) must(err error) {
+	if err != nil {
+		panic(mustParamPanic{err})
+	}
+}
+
+// recoverMustParam recovers the panics of the MustX accessors of Params raised within the request handler and
+// returns them as HTTPError with HTTP 400 (http.StatusBadRequest) status. Thus, they pass through the middleware stack
+// and reach the router's error handler, or DefaultErrorHandler when an error handler isn't configured.
+//
+// Other panics are raised again, so that they reach the panic handling of the server.
+func recoverMustParam(handler HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, route Route) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				p, ok := v.(mustParamPanic)
+				if !ok {
+					panic(v)
+				}
+				err = replyError(w, r, route, NewHTTPError(http.StatusBadRequest, p.err.Error()).Wrap(p.err))
+			}
+		}()
+
+		return handler(w, r, route)
+	}
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -1,8 +1,13 @@
 package shift
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestParams_Get(t *testing.T) {
@@ -183,3 +188,232 @@ func BenchmarkInternalParams_DeepCopy(b *testing.B) {
 		ip.deepCopy()
 	}
 }
+
+func TestParams_HostParams(t *testing.T) {
+	ip := newInternalParams(2)
+	ip.setKeys(&[]string{"id", "tenant"})
+	ip.appendValue("42")
+	ip.appendValue("path-tenant")
+
+	hp := newInternalParams(2)
+	hp.setKeys(&[]string{"tenant", "region"})
+	hp.appendValue("acme")
+	hp.appendValue("eu")
+
+	p := Params{internal: ip, host: hp}
+
+	assert(t, p.Len() == 4, fmt.Sprintf("len > expected: 4, got: %d", p.Len()))
+	assert(t, p.Get("id") == "42", fmt.Sprintf("id > expected: 42, got: %s", p.Get("id")))
+	assert(t, p.Get("region") == "eu", fmt.Sprintf("region > expected: eu, got: %s", p.Get("region")))
+	assert(t, p.Get("tenant") == "path-tenant", fmt.Sprintf("tenant > expected: path-tenant, got: %s", p.Get("tenant")))
+
+	m := p.Map()
+	assert(t, m["tenant"] == "path-tenant", fmt.Sprintf("map tenant > expected: path-tenant, got: %s", m["tenant"]))
+	assert(t, m["region"] == "eu", fmt.Sprintf("map region > expected: eu, got: %s", m["region"]))
+
+	expected := []Param{{"region", "eu"}, {"tenant", "acme"}, {"tenant", "path-tenant"}, {"id", "42"}}
+	slice := p.Slice()
+	assert(t, len(slice) == len(expected), fmt.Sprintf("slice len > expected: %d, got: %d", len(expected), len(slice)))
+	for i := 0; i < len(expected) && i < len(slice); i++ {
+		assert(t, slice[i] == expected[i], fmt.Sprintf("slice[%d] > expected: %v, got: %v", i, expected[i], slice[i]))
+	}
+
+	cp := p.Copy()
+	hp.reset()
+	assert(t, cp.Get("region") == "eu", fmt.Sprintf("copy region > expected: eu, got: %s", cp.Get("region")))
+}
+
+func typedTestParams() Params {
+	ip := newInternalParams(8)
+	ip.setKeys(&[]string{"empty", "date", "uuid", "flag", "neg", "id", "name"})
+	ip.appendValue("")
+	ip.appendValue("2024-03-15")
+	ip.appendValue("6BA7B810-9dad-11d1-80b4-00c04fd430c8")
+	ip.appendValue("true")
+	ip.appendValue("-7")
+	ip.appendValue("42")
+	ip.appendValue("max")
+	return newParams(ip)
+}
+
+func TestParams_Lookup(t *testing.T) {
+	p := typedTestParams()
+
+	v, ok := p.Lookup("empty")
+	assert(t, ok && v == "", fmt.Sprintf("empty > expected: true, got: %v %q", ok, v))
+
+	v, ok = p.Lookup("name")
+	assert(t, ok && v == "max", fmt.Sprintf("name > expected: max, got: %v %q", ok, v))
+
+	_, ok = p.Lookup("missing")
+	assert(t, !ok, "missing > expected: false, got: true")
+
+	var zero Params
+	_, ok = zero.Lookup("name")
+	assert(t, !ok, "zero params > expected: false, got: true")
+}
+
+func TestParams_TypedAccessors(t *testing.T) {
+	p := typedTestParams()
+
+	n, err := p.Int("id")
+	assert(t, err == nil && n == 42, fmt.Sprintf("int > expected: 42, got: %d %v", n, err))
+
+	n64, err := p.Int64("neg")
+	assert(t, err == nil && n64 == -7, fmt.Sprintf("int64 > expected: -7, got: %d %v", n64, err))
+
+	u, err := p.Uint("id")
+	assert(t, err == nil && u == 42, fmt.Sprintf("uint > expected: 42, got: %d %v", u, err))
+
+	b, err := p.Bool("flag")
+	assert(t, err == nil && b, fmt.Sprintf("bool > expected: true, got: %v %v", b, err))
+
+	id, err := p.UUID("uuid")
+	assert(t, err == nil && id.String() == "6ba7b810-9dad-11d1-80b4-00c04fd430c8", fmt.Sprintf("uuid > expected: 6ba7b810-9dad-11d1-80b4-00c04fd430c8, got: %s %v", id, err))
+
+	date, err := p.Time("date", "2006-01-02")
+	assert(t, err == nil && date.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)), fmt.Sprintf("time > expected: 2024-03-15, got: %s %v", date, err))
+
+	tt := []struct {
+		name  string
+		f     func() error
+		key   string
+		value string
+		err   error
+	}{
+		{name: "int missing", f: func() error { _, err := p.Int("missing"); return err }, key: "missing", err: ErrMissingParam},
+		{name: "int invalid", f: func() error { _, err := p.Int("name"); return err }, key: "name", value: "max", err: strconv.ErrSyntax},
+		{name: "int empty", f: func() error { _, err := p.Int("empty"); return err }, key: "empty", err: strconv.ErrSyntax},
+		{name: "uint negative", f: func() error { _, err := p.Uint("neg"); return err }, key: "neg", value: "-7", err: strconv.ErrSyntax},
+		{name: "bool invalid", f: func() error { _, err := p.Bool("id"); return err }, key: "id", value: "42", err: strconv.ErrSyntax},
+		{name: "uuid invalid", f: func() error { _, err := p.UUID("id"); return err }, key: "id", value: "42", err: ErrInvalidUUID},
+		{name: "time missing", f: func() error { _, err := p.Time("missing", "2006-01-02"); return err }, key: "missing", err: ErrMissingParam},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			err := tx.f()
+
+			var paramErr *ParamError
+			assert(t, errors.As(err, &paramErr), fmt.Sprintf("error > expected: ParamError, got: %v", err))
+			assert(t, paramErr.Key == tx.key && paramErr.Value == tx.value, fmt.Sprintf("param error > expected: %s %q, got: %s %q", tx.key, tx.value, paramErr.Key, paramErr.Value))
+			assert(t, errors.Is(err, tx.err), fmt.Sprintf("cause > expected: %v, got: %v", tx.err, err))
+		})
+	}
+}
+
+func TestParams_Must(t *testing.T) {
+	r := New()
+	r.UseErrorHandler(DefaultErrorHandler)
+
+	var mwErr error
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			mwErr = next(w, r, route)
+			return mwErr
+		}
+	})
+
+	r.GET("/users/:id/posts/:uuid", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "%d %s", route.Params.MustInt("id"), route.Params.MustUUID("uuid"))
+		return nil
+	})
+	r.GET("/panic/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		panic("boom")
+	})
+	r.GET("/recovered/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		func() {
+			defer func() { _ = recover() }()
+			route.Params.MustInt("id")
+		}()
+		panic("boom")
+	})
+	r.GET("/static", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "%d", route.Params.MustInt("id"))
+		return nil
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/users/42/posts/6ba7b810-9dad-11d1-80b4-00c04fd430c8", code: http.StatusOK, body: "42 6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{path: "/users/abc/posts/6ba7b810-9dad-11d1-80b4-00c04fd430c8", code: http.StatusBadRequest, body: "param id: invalid value \"abc\": strconv.Atoi: parsing \"abc\": invalid syntax\n"},
+		{path: "/users/42/posts/xyz", code: http.StatusBadRequest, body: "param uuid: invalid value \"xyz\": invalid uuid\n"},
+		{path: "/static", code: http.StatusBadRequest, body: "param id: missing route param\n"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.path, func(t *testing.T) {
+			mwErr = nil
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+
+			var paramErr *ParamError
+			assert(t, (tx.code == http.StatusBadRequest) == errors.As(mwErr, &paramErr), fmt.Sprintf("middleware error > got: %v", mwErr))
+		})
+	}
+
+	for _, path := range []string{"/panic/42", "/recovered/abc"} {
+		t.Run("other panics "+path, func(t *testing.T) {
+			defer func() {
+				assert(t, recover() == "boom", "expected the panic to propagate")
+			}()
+
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			srv.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
+
+func TestParams_Must_WithoutErrorHandler(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, _ = fmt.Fprintf(w, "%d", route.Params.MustInt("id"))
+		return nil
+	})
+	r.Host(":tenant.example.com", func(g *Group) {
+		g.GET("/users", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			_, _ = fmt.Fprintf(w, "%d", route.Params.MustInt("tenant"))
+			return nil
+		})
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		host string
+		path string
+		code int
+		body string
+	}{
+		{path: "/users/42", code: http.StatusOK, body: "42"},
+		{path: "/users/abc", code: http.StatusBadRequest, body: "param id: invalid value \"abc\": strconv.Atoi: parsing \"abc\": invalid syntax\n"},
+		{host: "acme.example.com", path: "/users", code: http.StatusBadRequest, body: "param tenant: invalid value \"acme\": strconv.Atoi: parsing \"acme\": invalid syntax\n"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.host+tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+			req.Host = tx.host
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+		})
+	}
+
+	// The pooled params are released unmarked, so the next request isn't affected.
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/7", nil)
+	srv.ServeHTTP(rw, req)
+	assert(t, rw.Code == http.StatusOK && rw.Body.String() == "7", fmt.Sprintf("response > expected: 200 7, got: %d %s", rw.Code, rw.Body.String()))
+}
@@ -0,0 +1,437 @@
+package shift
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp/syntax"
+	"strings"
+)
+
+// PatternSyntax is a route pattern syntax of another router, which can be translated into shift's route syntax.
+type PatternSyntax int
+
+const (
+	// ServeMuxSyntax is the pattern syntax of net/http ServeMux since Go 1.22.
+	// e.g.: GET example.com/users/{id}, /files/{path...}, /static/, /{$}
+	ServeMuxSyntax PatternSyntax = iota
+
+	// ChiSyntax is the pattern syntax of go-chi/chi.
+	// e.g.: /users/{id}, /users/{id:[0-9]+}, /files/*
+	ChiSyntax
+
+	// GorillaSyntax is the path template syntax of gorilla/mux.
+	// e.g.: /users/{id}, /users/{id:[0-9]+}, /files/{path:.*}
+	GorillaSyntax
+)
+
+func (s PatternSyntax) String() string {
+	switch s {
+	case ServeMuxSyntax:
+		return "servemux"
+	case ChiSyntax:
+		return "chi"
+	case GorillaSyntax:
+		return "gorilla"
+	default:
+		return fmt.Sprintf("PatternSyntax(%d)", int(s))
+	}
+}
+
+// ErrUnsupportedPattern is returned when a route pattern cannot be represented in shift's route syntax.
+var ErrUnsupportedPattern = errors.New("unsupported pattern")
+
+// reservedChars are the chars of shift's route syntax, which cannot be represented as literals.
+const reservedChars = ":*?<"
+
+// PatternWildcard is the name of the wildcard param translated from the anonymous wildcards.
+// i.e.: trailing slashes of ServeMux patterns and the * of chi patterns.
+const PatternWildcard = "rest"
+
+// Pattern translates a route pattern of the syntax into shift's route syntax.
+//
+//	shift.Pattern(shift.ServeMuxSyntax, "GET /users/{id}")   // GET /users/:id
+//	shift.Pattern(shift.ServeMuxSyntax, "/files/{path...}")  // /files/*path
+//	shift.Pattern(shift.ServeMuxSyntax, "/static/")          // /static/*rest
+//	shift.Pattern(shift.ChiSyntax, "/users/{id:[0-9]+}")     // /users/:id<[0-9]+>
+//	shift.Pattern(shift.ChiSyntax, "/files/*")               // /files/*rest
+//	shift.Pattern(shift.GorillaSyntax, "/files/{path:.*}")   // /files/*path
+//
+// The method and the host of ServeMux patterns are preserved. Regular expressions of chi and gorilla/mux patterns
+// become param constraints.
+//
+// It returns an error wrapping ErrUnsupportedPattern if the pattern is malformed or cannot be represented without
+// changing the routing behavior. e.g.: multiple wildcards, literal ':' or '*' chars and gorilla/mux regular expressions
+// matching slashes anywhere other than the end of the pattern.
+func Pattern(syntax PatternSyntax, pattern string) (string, error) {
+	method, host, path, err := translatePattern(syntax, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	if method != "" {
+		return method + " " + host + path, nil
+	}
+	return host + path, nil
+}
+
+// HandlePattern maps a request handler for the ServeMux pattern. See Pattern for the translation.
+//
+//	router.HandlePattern("GET /users/{id}", GetUser)
+//	router.HandlePattern("api.example.com/files/{path...}", GetFile) // All the methods.
+//
+// Patterns without a method are mapped to all the methods. Patterns with a host are mapped to the host.
+// As ServeMux, GET patterns are mapped to HEAD as well, unless a HEAD route is registered for the same path.
+// Unlike ServeMux, the request's host is matched including the port, unless Router.UseStripHostPort is enabled.
+//
+// HandlePattern panics if the pattern cannot be represented in shift's route syntax.
+func (c *Core) HandlePattern(pattern string, handler HandlerFunc) {
+	method, host, path, err := translatePattern(ServeMuxSyntax, pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	core := c
+	if host != "" {
+		if c.host != "" && c.host != host {
+			panic(fmt.Sprintf("pattern host %s conflicts with the group host %s", host, c.host))
+		}
+
+		scanHost(host)
+		core = &Core{
+			c.base,
+			c.logs,
+			c.misses,
+			c.scope,
+			c.mws,
+			c.name,
+			host,
+			c.meta,
+			c.matchers,
+		}
+	}
+
+	core.Map([]string{method}, path, handler)
+	if method == http.MethodGet {
+		core.mapImplicitHead(path, handler)
+	}
+}
+
+// mapImplicitHead maps the handler of a GET pattern for the HEAD method, unless a HEAD route has been registered for
+// the path. The HEAD route gives way to a HEAD route registered later for the path.
+func (c *Core) mapImplicitHead(path string, handler HandlerFunc) {
+	for _, log := range *c.logs {
+		if log.method == http.MethodHead && log.host == c.host && log.path == c.base+path {
+			return
+		}
+	}
+
+	c.Map([]string{http.MethodHead}, path, handler)
+	(*c.logs)[len(*c.logs)-1].implicit = true
+}
+
+// translatePattern translates the pattern into the method, the host and the path in shift's route syntax.
+func translatePattern(syntax PatternSyntax, pattern string) (method string, host string, path string, err error) {
+	unsupported := func(reason string) error {
+		return fmt.Errorf("%w: %s: %s", ErrUnsupportedPattern, pattern, reason)
+	}
+
+	switch syntax {
+	case ServeMuxSyntax:
+		method, host, path, err = translateServeMux(pattern, unsupported)
+	case ChiSyntax, GorillaSyntax:
+		path, err = translateBraces(syntax, pattern, unsupported)
+	default:
+		err = unsupported(fmt.Sprintf("unknown syntax %s", syntax))
+	}
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// Report the translations violating shift's own rules, e.g.: adjacent params.
+	if reason := recoverString(func() { scanPath(path) }); reason != "" {
+		return "", "", "", unsupported(reason)
+	}
+
+	return method, host, path, nil
+}
+
+func translateServeMux(pattern string, unsupported func(reason string) error) (method string, host string, path string, err error) {
+	rest := pattern
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method, rest = rest[:i], strings.TrimLeft(rest[i+1:], " \t")
+		if !isToken(method) {
+			return "", "", "", unsupported(fmt.Sprintf("invalid method %q", method))
+		}
+	}
+
+	i := strings.IndexByte(rest, '/')
+	if i == -1 {
+		return "", "", "", unsupported("missing path")
+	}
+
+	host, rest = rest[:i], rest[i:]
+	if strings.ContainsAny(host, "{}") {
+		return "", "", "", unsupported("host cannot contain wildcards")
+	}
+	if strings.IndexByte(host, ':') >= 0 {
+		return "", "", "", unsupported("host cannot contain a port")
+	}
+
+	var b strings.Builder
+	names := map[string]bool{}
+	segs := strings.Split(rest[1:], "/")
+	for i, seg := range segs {
+		b.WriteByte('/')
+		last := i == len(segs)-1
+
+		if !strings.ContainsAny(seg, "{}") {
+			if strings.ContainsAny(seg, reservedChars) {
+				return "", "", "", unsupported(fmt.Sprintf("literal %q cannot be represented", seg))
+			}
+
+			b.WriteString(seg)
+			if last && seg == "" {
+				// Trailing slash matches the whole subtree.
+				if names[PatternWildcard] {
+					return "", "", "", unsupported(fmt.Sprintf("param %s conflicts with the wildcard", PatternWildcard))
+				}
+				b.WriteString("*" + PatternWildcard)
+			}
+			continue
+		}
+
+		if seg[0] != '{' || seg[len(seg)-1] != '}' || strings.Count(seg, "{") != 1 || strings.Count(seg, "}") != 1 {
+			return "", "", "", unsupported(fmt.Sprintf("wildcard %q must be a whole segment", seg))
+		}
+
+		name := seg[1 : len(seg)-1]
+		if name == "$" {
+			if !last {
+				return "", "", "", unsupported("{$} must be at the end")
+			}
+			// Matches the path with the trailing slash only.
+			continue
+		}
+
+		multi := strings.HasSuffix(name, "...")
+		if multi {
+			if !last {
+				return "", "", "", unsupported(fmt.Sprintf("%s must be at the end", seg))
+			}
+			name = name[:len(name)-3]
+		}
+
+		if err := checkParamName(name, names, unsupported); err != nil {
+			return "", "", "", err
+		}
+
+		if multi {
+			b.WriteString("*" + name)
+		} else {
+			b.WriteString(":" + name)
+		}
+	}
+
+	return method, host, b.String(), nil
+}
+
+// translateBraces translates the {name} and {name:regexp} params of chi and gorilla/mux patterns.
+func translateBraces(syntax PatternSyntax, pattern string, unsupported func(reason string) error) (string, error) {
+	if pattern == "" || pattern[0] != '/' {
+		return "", unsupported("path must have a leading slash")
+	}
+
+	var b strings.Builder
+	names := map[string]bool{}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '{':
+			end := closingBrace(pattern, i)
+			if end == -1 {
+				return "", unsupported("unclosed {")
+			}
+
+			name, expr, hasExpr := strings.Cut(pattern[i+1:end], ":")
+			i = end
+
+			if err := checkParamName(name, names, unsupported); err != nil {
+				return "", err
+			}
+
+			if !hasExpr {
+				b.WriteString(":" + name)
+				continue
+			}
+
+			param, err := translateRegexp(syntax, name, expr, end == len(pattern)-1, unsupported)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(param)
+		case '}':
+			return "", unsupported("unexpected }")
+		case '*':
+			if syntax != ChiSyntax {
+				return "", unsupported("literal * cannot be represented")
+			}
+			if i != len(pattern)-1 {
+				return "", unsupported("* must be at the end")
+			}
+			if names[PatternWildcard] {
+				return "", unsupported(fmt.Sprintf("param %s conflicts with the wildcard", PatternWildcard))
+			}
+			b.WriteString("*" + PatternWildcard)
+		case ':', '?', '<':
+			return "", unsupported(fmt.Sprintf("literal %c cannot be represented", c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// translateRegexp translates a param with a regular expression into a constrained param.
+// gorilla/mux params whose regular expressions match slashes are translated into wildcards when they are equivalent.
+func translateRegexp(ps PatternSyntax, name string, expr string, last bool, unsupported func(reason string) error) (string, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return "", unsupported(fmt.Sprintf("invalid regexp of %s: %v", name, err))
+	}
+
+	// chi matches params up to the next slash, while gorilla/mux matches the regexp against the remaining path.
+	if ps == GorillaSyntax && matchesSlash(re) {
+		if expr == ".*" && last {
+			return "*" + name, nil
+		}
+		return "", unsupported(fmt.Sprintf("regexp of %s matching slashes can only be .* at the end", name))
+	}
+
+	if strings.IndexByte(expr, '/') >= 0 || !balancedAngles(expr) {
+		return "", unsupported(fmt.Sprintf("regexp of %s cannot be represented as a constraint", name))
+	}
+
+	// Wrap the expressions looking like typed constraint names. e.g.: int
+	if isParamName(expr) {
+		expr = "(?:" + expr + ")"
+	}
+
+	return ":" + name + "<" + expr + ">", nil
+}
+
+// checkParamName reports an invalid or a duplicate param name, and records the name.
+func checkParamName(name string, names map[string]bool, unsupported func(reason string) error) error {
+	if !isParamName(name) {
+		return unsupported(fmt.Sprintf("invalid param name %q", name))
+	}
+
+	if names[name] {
+		return unsupported(fmt.Sprintf("duplicate param name %s", name))
+	}
+
+	names[name] = true
+	return nil
+}
+
+func isParamName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isParamNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// closingBrace returns the index of the brace closing the brace at the index i, or -1 if it's unclosed.
+// Braces of the regexp quantifiers are balanced. e.g.: {id:[0-9]{3}}
+func closingBrace(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// balancedAngles reports whether the angle brackets of the regexp are balanced, so that it can be enclosed in a constraint.
+func balancedAngles(expr string) bool {
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// matchesSlash reports whether the regexp can match a slash.
+func matchesSlash(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '/' {
+				return true
+			}
+		}
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '/' && '/' <= re.Rune[i+1] {
+				return true
+			}
+		}
+	}
+
+	for _, sub := range re.Sub {
+		if matchesSlash(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// isToken reports whether the string is a valid HTTP method token.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// recoverString executes f and returns the string panicked by f, if any.
+func recoverString(f func()) (s string) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			s = fmt.Sprint(rec)
+		}
+	}()
+
+	f()
+	return
+}
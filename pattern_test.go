@@ -0,0 +1,168 @@
+package shift
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPattern(t *testing.T) {
+	tt := []struct {
+		syntax   PatternSyntax
+		pattern  string
+		expected string
+	}{
+		{syntax: ServeMuxSyntax, pattern: "/users", expected: "/users"},
+		{syntax: ServeMuxSyntax, pattern: "GET /users/{id}", expected: "GET /users/:id"},
+		{syntax: ServeMuxSyntax, pattern: "POST  /users/{id}/posts/{post}", expected: "POST /users/:id/posts/:post"},
+		{syntax: ServeMuxSyntax, pattern: "/files/{path...}", expected: "/files/*path"},
+		{syntax: ServeMuxSyntax, pattern: "/static/", expected: "/static/*rest"},
+		{syntax: ServeMuxSyntax, pattern: "/", expected: "/*rest"},
+		{syntax: ServeMuxSyntax, pattern: "/{$}", expected: "/"},
+		{syntax: ServeMuxSyntax, pattern: "/posts/{$}", expected: "/posts/"},
+		{syntax: ServeMuxSyntax, pattern: "GET api.example.com/users/{id}", expected: "GET api.example.com/users/:id"},
+		{syntax: ChiSyntax, pattern: "/users/{id}", expected: "/users/:id"},
+		{syntax: ChiSyntax, pattern: "/users/{id:[0-9]+}", expected: "/users/:id<[0-9]+>"},
+		{syntax: ChiSyntax, pattern: "/codes/{code:[A-Z]{3}}", expected: "/codes/:code<[A-Z]{3}>"},
+		{syntax: ChiSyntax, pattern: "/files/{name}.{ext}", expected: "/files/:name.:ext"},
+		{syntax: ChiSyntax, pattern: "/files/*", expected: "/files/*rest"},
+		{syntax: ChiSyntax, pattern: "/types/{t:int}", expected: "/types/:t<(?:int)>"},
+		{syntax: ChiSyntax, pattern: "/any/{v:.*}", expected: "/any/:v<.*>"},
+		{syntax: GorillaSyntax, pattern: "/users/{id}", expected: "/users/:id"},
+		{syntax: GorillaSyntax, pattern: "/users/{id:[0-9]+}/posts", expected: "/users/:id<[0-9]+>/posts"},
+		{syntax: GorillaSyntax, pattern: "/files/{path:.*}", expected: "/files/*path"},
+		{syntax: GorillaSyntax, pattern: "/{from}-{to}", expected: "/:from-:to"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.syntax.String()+" "+tx.pattern, func(t *testing.T) {
+			p, err := Pattern(tx.syntax, tx.pattern)
+			assert(t, err == nil, fmt.Sprintf("error > expected: <nil>, got: %v", err))
+			assert(t, p == tx.expected, fmt.Sprintf("pattern > expected: %s, got: %s", tx.expected, p))
+		})
+	}
+}
+
+func TestPattern_Unsupported(t *testing.T) {
+	tt := []struct {
+		syntax  PatternSyntax
+		pattern string
+	}{
+		{syntax: ServeMuxSyntax, pattern: "GET"},
+		{syntax: ServeMuxSyntax, pattern: "G(T /users"},
+		{syntax: ServeMuxSyntax, pattern: "/files/{a...}/{b...}"},
+		{syntax: ServeMuxSyntax, pattern: "/files/{path...}/raw"},
+		{syntax: ServeMuxSyntax, pattern: "/users/{id}/{id}"},
+		{syntax: ServeMuxSyntax, pattern: "/users/id{id}"},
+		{syntax: ServeMuxSyntax, pattern: "/users/{}"},
+		{syntax: ServeMuxSyntax, pattern: "/{$}/users"},
+		{syntax: ServeMuxSyntax, pattern: "/users:search"},
+		{syntax: ServeMuxSyntax, pattern: "/{rest}/"},
+		{syntax: ServeMuxSyntax, pattern: "{tenant}.example.com/"},
+		{syntax: ServeMuxSyntax, pattern: "example.com:8080/"},
+		{syntax: ChiSyntax, pattern: "users"},
+		{syntax: ChiSyntax, pattern: "/files/*/raw"},
+		{syntax: ChiSyntax, pattern: "/users/{id"},
+		{syntax: ChiSyntax, pattern: "/users/id}"},
+		{syntax: ChiSyntax, pattern: "/users/{id:[}"},
+		{syntax: ChiSyntax, pattern: "/users/{a}{b}"},
+		{syntax: ChiSyntax, pattern: "/users/{id:a/b}"},
+		{syntax: ChiSyntax, pattern: "/users/{id:a>b}"},
+		{syntax: ChiSyntax, pattern: "/users/{id}?"},
+		{syntax: GorillaSyntax, pattern: "/files/*"},
+		{syntax: GorillaSyntax, pattern: "/files/{path:.*}/raw"},
+		{syntax: GorillaSyntax, pattern: "/files/{path:.+}"},
+		{syntax: GorillaSyntax, pattern: "/files/{path:[a-z/]+}"},
+		{syntax: PatternSyntax(42), pattern: "/users"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.syntax.String()+" "+tx.pattern, func(t *testing.T) {
+			p, err := Pattern(tx.syntax, tx.pattern)
+			assert(t, errors.Is(err, ErrUnsupportedPattern), fmt.Sprintf("error > expected: %v, got: %v (%s)", ErrUnsupportedPattern, err, p))
+		})
+	}
+}
+
+func TestCore_HandlePattern(t *testing.T) {
+	r := newTestRouter()
+	rec := &routeRecorder{}
+
+	r.HandlePattern("GET /users/{id}", rec.Handler())
+	r.HandlePattern("/files/{path...}", rec.Handler())
+	r.HandlePattern("/static/", rec.Handler())
+	r.HandlePattern("GET /{$}", rec.Handler())
+	r.Group("/v1", func(g *Group) {
+		g.HandlePattern("DELETE /users/{id}", rec.Handler())
+	})
+
+	tt := srvTestTable{
+		srvTestItem{method: http.MethodGet, path: "/users/42", valid: true, pathTemplate: "/users/:id", params: map[string]string{"id": "42"}},
+		srvTestItem{method: http.MethodPost, path: "/users/42", valid: false},
+		srvTestItem{method: http.MethodPut, path: "/files/a/b.txt", valid: true, pathTemplate: "/files/*path", params: map[string]string{"path": "a/b.txt"}},
+		srvTestItem{method: http.MethodGet, path: "/static/css/app.css", valid: true, pathTemplate: "/static/*rest", params: map[string]string{"rest": "css/app.css"}},
+		srvTestItem{method: http.MethodGet, path: "/", valid: true, pathTemplate: "/"},
+		srvTestItem{method: http.MethodDelete, path: "/v1/users/42", valid: true, pathTemplate: "/v1/users/:id", params: map[string]string{"id": "42"}},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+
+	t.Run("host", func(t *testing.T) {
+		r := newTestRouter()
+		r.HandlePattern("GET api.example.com/users/{id}", fakeHandler())
+		srv := r.Serve()
+
+		for host, code := range map[string]int{"api.example.com": http.StatusOK, "www.example.com": http.StatusNotFound} {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			req.Host = host
+			srv.ServeHTTP(rw, req)
+			assert(t, rw.Code == code, fmt.Sprintf("%s > status code expected: %d, got: %d", host, code, rw.Code))
+		}
+	})
+
+	t.Run("head", func(t *testing.T) {
+		r := newTestRouter()
+		r.HandlePattern("GET /users/{id}", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			_, err := w.Write([]byte("get"))
+			return err
+		})
+		r.HandlePattern("GET /files/{path...}", fakeHandler())
+		r.HandlePattern("HEAD /files/{path...}", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		})
+		r.HEAD("/assets/*rest", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			w.WriteHeader(http.StatusAccepted)
+			return nil
+		})
+		r.HandlePattern("GET /assets/", fakeHandler())
+		srv := r.Serve()
+
+		for path, code := range map[string]int{"/users/42": http.StatusOK, "/files/a.txt": http.StatusNoContent, "/assets/app.css": http.StatusAccepted} {
+			rw := httptest.NewRecorder()
+			srv.ServeHTTP(rw, httptest.NewRequest(http.MethodHead, path, nil))
+			assert(t, rw.Code == code, fmt.Sprintf("HEAD %s > status code expected: %d, got: %d", path, code, rw.Code))
+		}
+	})
+
+	t.Run("conflicting host", func(t *testing.T) {
+		r := newTestRouter()
+		pnk := panicHandler(func() {
+			r.Host("api.example.com", func(g *Group) {
+				g.HandlePattern("www.example.com/users", fakeHandler())
+			})
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		r := newTestRouter()
+		pnk := panicHandler(func() {
+			r.HandlePattern("/files/{path...}/raw", fakeHandler())
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+}
@@ -14,8 +14,14 @@ type node struct {
 	param     *node
 	wildcard  *node
 	handler   HandlerFunc
+	meta      *RouteMeta
 	paramKeys *[]string // Nil paramKeys denote the route is static.
-	index     struct {
+
+	// Param nodes with constraints. They are evaluated in the registration order before falling back to the param node.
+	constrainedParams []*node
+	constraint        *paramConstraint // Non-nil only for constrained param nodes.
+
+	index struct {
 		minChar uint8
 		maxChar uint8
 
@@ -63,28 +69,68 @@ func newRootNode() *node {
 	}
 }
 
-func (n *node) insert(path string, handler HandlerFunc) (varsCount int) {
+// insert registers the handler at the path. Paths with optional segments are expanded into a node for each
+// combination of the optional segments, all of which share the path as the template.
+func (n *node) insert(path string, handler HandlerFunc, meta *RouteMeta, constraints map[string]ConstraintFunc) (varsCount int) {
 	varsCount = scanPath(path)
 
+	for _, expanded := range expandOptionalSegments(path) {
+		n.insertPath(expanded, path, handler, meta, constraints)
+	}
+	return
+}
+
+func (n *node) insertPath(path string, template string, handler HandlerFunc, meta *RouteMeta, constraints map[string]ConstraintFunc) {
 	if path == "" {
 		// Root node.
 		n.template = "/"
 		n.handler = handler
+		n.meta = meta
 		return
 	}
 
-	newNode, paramKeys := n.addNode(path)
+	newNode, paramKeys := n.addNode(path, constraints)
 	if newNode.handler != nil {
-		panic(fmt.Sprintf("%s conflicts with already registered route %s", path, newNode.template))
+		panic(fmt.Sprintf("%s conflicts with already registered route %s", template, newNode.template))
 	}
 
-	newNode.template = path
+	newNode.template = template
 	newNode.handler = handler
+	newNode.meta = meta
 	if len(paramKeys) > 0 {
 		rs := reverseSlice(paramKeys)
 		newNode.paramKeys = &rs
 	}
-	return
+}
+
+// expandOptionalSegments returns the paths matching the path with and without its trailing optional segments.
+//
+//	e.g.:
+//	/archive/:year?/:month? -> /archive, /archive/:year, /archive/:year/:month
+//	/archive                -> /archive
+func expandOptionalSegments(path string) []string {
+	r := newRouteScanner(path)
+	for seg := r.next(); seg != ""; seg = r.next() {
+		if seg[0] != ':' || !isOptionalParam(seg) {
+			continue
+		}
+
+		// Optional segments are trailing segments which span the whole segment. See scanPath.
+		required := path[:r.low-len(seg)-1]
+		paths := []string{required}
+		if required == "" {
+			paths[0] = "/"
+		}
+
+		current := required
+		for _, opt := range strings.Split(path[len(required)+1:], "/") {
+			current += "/" + opt[:len(opt)-1]
+			paths = append(paths, current)
+		}
+		return paths
+	}
+
+	return []string{path}
 }
 
 func reverseSlice(s []string) (rs []string) {
@@ -96,7 +142,7 @@ func reverseSlice(s []string) (rs []string) {
 	return s
 }
 
-func (n *node) addNode(path string) (root *node, paramKeys []string) {
+func (n *node) addNode(path string, constraints map[string]ConstraintFunc) (root *node, paramKeys []string) {
 	if path[0] == '/' {
 		path = path[1:]
 	}
@@ -107,7 +153,14 @@ func (n *node) addNode(path string) (root *node, paramKeys []string) {
 	for seg := r.next(); seg != ""; seg = r.next() {
 		switch seg[0] {
 		case ':':
-			paramKeys = append(paramKeys, seg[1:])
+			name, expr := splitParam(seg)
+			paramKeys = append(paramKeys, name)
+
+			if expr != "" {
+				root = root.addConstrainedParam(expr, constraints)
+				continue
+			}
+
 			if root.param != nil {
 				root = root.param
 				continue
@@ -195,6 +248,20 @@ func (n *node) addNode(path string) (root *node, paramKeys []string) {
 	return root, paramKeys
 }
 
+// addConstrainedParam returns the constrained param node matching the constraint expression.
+// If there's no such node, a new node is appended to the constrained param nodes.
+func (n *node) addConstrainedParam(expr string, constraints map[string]ConstraintFunc) *node {
+	for _, param := range n.constrainedParams {
+		if param.constraint.expr == expr {
+			return param
+		}
+	}
+
+	param := &node{prefix: ":", constraint: compileConstraint(expr, constraints)}
+	n.constrainedParams = append(n.constrainedParams, param)
+	return param
+}
+
 // findCandidateByCharAndSize search for a children by matching the first char and length.
 // If no match is found, it looks up indexer#trailingSlash to see if there's a possible match who has a trailing slash.
 // If found, returns the found children with trailing slash and true for the 2nd return value.
@@ -331,8 +398,22 @@ func (n *node) searchRecursion(path string, params *internalParams, paramInjecto
 	}
 
 	// Couldn't find a matching node within children nodes.
-	// So lets fallback to param node.
+	// So lets fallback to constrained param nodes.
+	if len(n.constrainedParams) > 0 {
+		if child, ps := n.searchConstrainedParams(path, params, paramInjector); child != nil {
+			return child, ps
+		}
+	}
+
+	// Still no luck, lets fallback to param node.
 	if n.param != nil {
+		// Look for the delimiters within the segment when the param is followed by another param within the same segment.
+		if n.param.hasDelimiters() {
+			if child, ps := n.param.searchDelimited(path, params, paramInjector); child != nil {
+				return child, ps
+			}
+		}
+
 		// Check if more sections are left to match in the path.
 
 		// When idx == 0, it means param value in the path is empty.
@@ -373,64 +454,208 @@ func (n *node) searchRecursion(path string, params *internalParams, paramInjecto
 	return nil, params
 }
 
+// searchConstrainedParams traverses the constrained param nodes in the registration order looking for a matching node.
+// A constrained param node is traversed only when the param value in the path satisfies its constraint.
+// Otherwise, it backtracks to the next constrained param node.
+func (n *node) searchConstrainedParams(path string, params *internalParams, paramInjector func() *internalParams) (*node, *internalParams) {
+	idx := strings.IndexByte(path, '/')
+	if idx == 0 {
+		// Param value in the path is empty.
+		return nil, params
+	}
+
+	value := path
+	if idx > 0 {
+		value = path[:idx]
+	}
+
+	for _, param := range n.constrainedParams {
+		if param.hasDelimiters() {
+			if child, ps := param.searchDelimited(path, params, paramInjector); child != nil {
+				return child, ps
+			}
+		}
+
+		if !param.constraint.match(value) {
+			continue
+		}
+
+		if idx > 0 {
+			// Traverse the constrained param node until all the path sections are matched.
+			if innerChild, ps := param.searchRecursion(path[idx:], params, paramInjector); innerChild != nil && innerChild.handler != nil {
+				ps.appendValue(value)
+				return innerChild, ps
+			}
+		} else if param.handler != nil {
+			params = paramInjector()
+			params.setKeys(param.paramKeys)
+			params.appendValue(value)
+			return param, params
+		}
+	}
+
+	return nil, params
+}
+
+// searchDelimited traverses the param node with the param value ending at a delimiter within the first segment of the
+// path. A delimiter is a char beginning a static child of the param node other than a slash. e.g.: '.' in /:name.:ext
+//
+// Delimiters are tried from the end of the segment, so that the param value is the longest value yielding a match.
+func (n *node) searchDelimited(path string, params *internalParams, paramInjector func() *internalParams) (*node, *internalParams) {
+	end := strings.IndexByte(path, '/')
+	if end == -1 {
+		end = len(path)
+	}
+
+	for i := end - 1; i > 0; i-- {
+		if !n.isDelimiter(path[i]) {
+			continue
+		}
+
+		value := path[:i]
+		if n.constraint != nil && !n.constraint.match(value) {
+			continue
+		}
+
+		if child, ps := n.searchRecursion(path[i:], params, paramInjector); child != nil && child.handler != nil {
+			ps.appendValue(value)
+			return child, ps
+		}
+	}
+
+	return nil, params
+}
+
+// hasDelimiters reports whether the node has a static child beginning with a char other than a slash.
+func (n *node) hasDelimiters() bool {
+	return len(n.children) > 0 && (n.index.minChar != '/' || n.index.maxChar != '/')
+}
+
+// isDelimiter reports whether the node has a static child beginning with the char, which is not a slash.
+func (n *node) isDelimiter(c uint8) bool {
+	return c != '/' && n.index.minChar <= c && c <= n.index.maxChar && n.index.indices[c-n.index.minChar] != 0
+}
+
+// scanPath validates the path and returns the number of params and wildcards within the path.
 func scanPath(path string) (varsCount int) {
 	if path == "" || path[0] != '/' {
 		panic("path must have a leading slash")
 	}
 
-	inParams := false
-	inWC := false
-	for i, c := range []byte(path) {
+	for _, c := range []byte(path) {
 		if unicode.IsSpace(rune(c)) {
 			panic("path shouldn't contain any whitespace")
 		}
+	}
+
+	optional := false   // Denotes an optional segment has been scanned.
+	afterParam := false // Denotes the previous token is a param within the same segment.
+	for i := 0; i < len(path); {
+		switch c := path[i]; c {
+		case '*':
+			if afterParam {
+				panic("wildcard segment shouldn't follow the param segment within the same scope")
+			}
+
+			if optional {
+				panic("only optional param segments can follow an optional param segment")
+			}
+
+			name := path[i+1:]
+			if name == "" {
+				panic("wildcard must have a name")
+			}
 
-		if inWC {
-			switch c {
-			case '/', ':':
+			if strings.ContainsAny(name, "/:") {
 				panic("another segment shouldn't follow a wildcard segment")
-			case '*':
+			}
+
+			if strings.IndexByte(name, '*') != -1 {
 				panic("only one wildcard segment is allowed")
 			}
-		}
 
-		if inParams {
-			switch c {
-			case '/':
-				if path[i-1] == ':' {
-					panic("param must have a name")
+			return varsCount + 1
+		case ':':
+			if afterParam {
+				panic("params within the same segment must be separated by a delimiter")
+			}
+
+			j := scanParam(path, i)
+			if isOptionalParam(path[i:j]) {
+				if path[i-1] != '/' || (j < len(path) && path[j] != '/') {
+					panic("optional param must span the whole segment")
 				}
-				inParams = false
-				continue
-			case ':':
-				panic("only one param segment is allowed within the same scope")
-			case '*':
-				panic("wildcard segment shouldn't follow the param segment within the same scope")
+				optional = true
+			} else if optional {
+				panic("only optional param segments can follow an optional param segment")
 			}
-		}
 
-		if c == '*' {
-			inWC = true
+			afterParam = true
 			varsCount++
+			i = j
 			continue
+		case '/':
+			if optional && (i+1 == len(path) || path[i+1] != ':') {
+				panic("only optional param segments can follow an optional param segment")
+			}
+			afterParam = false
+		default:
+			afterParam = false
+		}
+		i++
+	}
+
+	return
+}
+
+// scanParam validates the param segment starting at the index i and returns the index following the segment.
+func scanParam(path string, i int) int {
+	j := paramNameEnd(path, i+1)
+
+	if j == i+1 {
+		panic("param must have a name")
+	}
+
+	if j < len(path) && path[j] == '<' {
+		depth := 0
+	Constraint:
+		for ; j < len(path); j++ {
+			switch path[j] {
+			case '<':
+				depth++
+			case '>':
+				depth--
+				if depth == 0 {
+					if path[j-1] == '<' {
+						panic("param constraint cannot be empty")
+					}
+					j++
+					break Constraint
+				}
+			case '/':
+				panic("param constraint shouldn't contain a slash")
+			}
 		}
 
-		if c == ':' {
-			inParams = true
-			varsCount++
-			continue
+		if depth > 0 {
+			panic("param constraint must be closed")
 		}
 	}
 
-	if inParams && path[len(path)-1] == ':' {
-		panic("param must have a name")
+	if j < len(path) && path[j] == '?' {
+		j++
 	}
 
-	if inWC && path[len(path)-1] == '*' {
-		panic("wildcard must have a name")
+	if j < len(path) && isParamNameChar(path[j]) {
+		panic("param constraint must be at the end of the param")
 	}
 
-	return
+	return j
+}
+
+// isOptionalParam reports whether the param segment is marked as optional. e.g.: :month?
+func isOptionalParam(seg string) bool {
+	return len(seg) > 0 && seg[len(seg)-1] == '?'
 }
 
 func (n *node) caseInsensitiveSearch(path string, paramInjector func() *internalParams) (*node, *internalParams, string) {
@@ -522,8 +747,21 @@ TraverseChild:
 		}
 	}
 
+	// Fallback to constrained param nodes.
+	if len(n.constrainedParams) > 0 {
+		if child, ps := n.caseInsensitiveSearchConstrainedParams(path, params, paramInjector, buf); child != nil {
+			return child, ps
+		}
+	}
+
 	// Fallback to param node.
 	if n.param != nil {
+		if n.param.hasDelimiters() {
+			if child, ps := n.param.caseInsensitiveSearchDelimited(path, params, paramInjector, buf); child != nil {
+				return child, ps
+			}
+		}
+
 		// Check if more segments are left to cover in the searching path.
 		if idx := strings.IndexByte(path, '/'); idx == -1 {
 
@@ -570,6 +808,74 @@ TraverseChild:
 	return nil, params
 }
 
+// caseInsensitiveSearchConstrainedParams is the case-insensitive counterpart of searchConstrainedParams.
+func (n *node) caseInsensitiveSearchConstrainedParams(path string, params *internalParams, paramInjector func() *internalParams, buf reverseBuffer) (*node, *internalParams) {
+	idx := strings.IndexByte(path, '/')
+	if idx == 0 {
+		return nil, params
+	}
+
+	value := path
+	if idx > 0 {
+		value = path[:idx]
+	}
+
+	for _, param := range n.constrainedParams {
+		if param.hasDelimiters() {
+			if child, ps := param.caseInsensitiveSearchDelimited(path, params, paramInjector, buf); child != nil {
+				return child, ps
+			}
+		}
+
+		if !param.constraint.match(value) {
+			continue
+		}
+
+		if idx > 0 {
+			if child, ps := param.caseInsensitiveSearchRecursion(path[idx:], params, paramInjector, buf); child != nil && child.handler != nil {
+				ps.appendValue(value)
+				buf.WriteString(value)
+				return child, ps
+			}
+		} else if param.handler != nil {
+			params = paramInjector()
+			params.setKeys(param.paramKeys)
+			params.appendValue(value)
+			buf.WriteString(value)
+			return param, params
+		}
+	}
+
+	return nil, params
+}
+
+// caseInsensitiveSearchDelimited is the case-insensitive counterpart of searchDelimited.
+func (n *node) caseInsensitiveSearchDelimited(path string, params *internalParams, paramInjector func() *internalParams, buf reverseBuffer) (*node, *internalParams) {
+	end := strings.IndexByte(path, '/')
+	if end == -1 {
+		end = len(path)
+	}
+
+	for i := end - 1; i > 0; i-- {
+		if !n.isDelimiter(path[i]) {
+			continue
+		}
+
+		value := path[:i]
+		if n.constraint != nil && !n.constraint.match(value) {
+			continue
+		}
+
+		if child, ps := n.caseInsensitiveSearchRecursion(path[i:], params, paramInjector, buf); child != nil && child.handler != nil {
+			ps.appendValue(value)
+			buf.WriteString(value)
+			return child, ps
+		}
+	}
+
+	return nil, params
+}
+
 func findParamsCount(path string) (c int) {
 	for _, b := range []byte(path) {
 		if b == ':' || b == '*' {
@@ -3,6 +3,7 @@ package shift
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -50,7 +51,7 @@ func TestStatic(t *testing.T) {
 
 	paramsCount := 0
 	for _, path := range paths {
-		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 		pc := findParamsCount(path)
 		if pc > paramsCount {
 			paramsCount = pc
@@ -121,7 +122,7 @@ func TestDynamicRoutes(t *testing.T) {
 
 	paramsCount := 0
 	for _, path := range paths {
-		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 		pc := findParamsCount(path)
 		if pc > paramsCount {
 			paramsCount = pc
@@ -197,7 +198,7 @@ func TestDynamicRoutesWithParams(t *testing.T) {
 
 	maxParams := 0
 	for _, path := range paths {
-		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 		pc := findParamsCount(path)
 		if pc > maxParams {
@@ -255,7 +256,7 @@ func TestWildcard(t *testing.T) {
 
 	paramsCount := 0
 	for _, path := range paths {
-		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 		pc := findParamsCount(path)
 		if pc > paramsCount {
 			paramsCount = pc
@@ -302,7 +303,7 @@ func TestWildcardParams(t *testing.T) {
 
 	maxParams := 0
 	for _, path := range paths {
-		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 		pc := findParamsCount(path)
 		if pc > maxParams {
@@ -345,7 +346,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -371,7 +372,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -396,7 +397,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -421,7 +422,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -446,7 +447,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -472,7 +473,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -498,7 +499,7 @@ func TestNode_Search_TraversalPathChange(t *testing.T) {
 
 		maxParams := 0
 		for _, path := range paths {
-			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler))
+			tree.insert(path, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 
 			pc := findParamsCount(path)
 			if pc > maxParams {
@@ -536,7 +537,7 @@ func BenchmarkSimple(b *testing.B) {
 
 	paramsCount := 0
 	for _, route := range routes {
-		tree.insert(route, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(route, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 		pc := findParamsCount(route)
 		if pc > paramsCount {
 			paramsCount = pc
@@ -604,7 +605,7 @@ func BenchmarkSimple2(b *testing.B) {
 
 	paramsCount := 0
 	for _, route := range routes {
-		tree.insert(route, HTTPHandlerFunc(fakeHttpHandler))
+		tree.insert(route, HTTPHandlerFunc(fakeHttpHandler), nil, nil)
 		pc := findParamsCount(route)
 		if pc > paramsCount {
 			paramsCount = pc
@@ -771,6 +772,22 @@ func TestScanPath(t *testing.T) {
 			"/foo/:bar_:baz",
 			"/foo/:bar_:baz/xyz",
 			"/foo/:bar_*abc",
+
+			// Malformed constraints.
+			"/:<int>",
+			"/foo/:id<>",
+			"/foo/:id<int",
+			"/foo/:id<[a-z]+/bar>",
+			"/foo/:id<int>bar",
+			"/foo/:id<int>:name",
+
+			// Misplaced optional params.
+			"/:foo?/bar",
+			"/:foo?/:bar",
+			"/foo/x:bar?",
+			"/foo/:bar?.txt",
+			"/foo/:bar.:baz?",
+			"/foo/:bar?/*baz",
 		}
 
 		for _, path := range paths {
@@ -792,6 +809,13 @@ func TestScanPath(t *testing.T) {
 			"/:foo/:bar/*baz":      3,
 			"/:foo/:bar/:baz/:abc": 4,
 			"/:foo/:bar/:baz/*abc": 4,
+			"/:foo<int>/*bar":      2,
+			"/:foo<[a-z:*]+>/:bar": 2,
+			"/:foo.:bar":           2,
+			"/:foo-:bar/*baz":      3,
+			"/:foo/:bar?":          2,
+			"/:foo/:bar?/:baz?":    3,
+			"/:foo<int>?":          1,
 		}
 
 		for path, c := range paths {
@@ -801,6 +825,22 @@ func TestScanPath(t *testing.T) {
 	})
 }
 
+func TestExpandOptionalSegments(t *testing.T) {
+	table := map[string][]string{
+		"/foo/:bar":               {"/foo/:bar"},
+		"/:foo?":                  {"/", "/:foo"},
+		"/archive/:year/:month?":  {"/archive/:year", "/archive/:year/:month"},
+		"/archive/:year?/:month?": {"/archive", "/archive/:year", "/archive/:year/:month"},
+		"/posts/:id<[a-z?]+>/:p?": {"/posts/:id<[a-z?]+>", "/posts/:id<[a-z?]+>/:p"},
+		"/reports/:date<date>?":   {"/reports", "/reports/:date<date>"},
+	}
+
+	for path, expected := range table {
+		paths := expandOptionalSegments(path)
+		assert(t, reflect.DeepEqual(paths, expected), fmt.Sprintf("path %s > expected: %v, got: %v", path, expected, paths))
+	}
+}
+
 func panicHandler(f func()) (rec any) {
 	defer func() {
 		rec = recover()
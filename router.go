@@ -2,6 +2,7 @@ package shift
 
 import (
 	"net/http"
+	"sort"
 )
 
 type routingBehavior uint8
@@ -13,10 +14,19 @@ const (
 )
 
 type Config struct {
-	trailingSlashMatch     *actionConfig
-	pathCorrectionMatch    *actionConfig
-	notFoundHandler        func(w http.ResponseWriter, r *http.Request)
-	handleMethodNotAllowed bool
+	trailingSlashMatch      *actionConfig
+	pathCorrectionMatch     *actionConfig
+	notFoundHandler         func(w http.ResponseWriter, r *http.Request)
+	methodNotAllowedHandler func(w http.ResponseWriter, r *http.Request, allowed []string)
+	errorHandler            ErrorHandlerFunc
+	constraints             map[string]ConstraintFunc
+	stripHostPort           bool
+	autoHead                bool
+	autoOptionsHandler      func(w http.ResponseWriter, r *http.Request)
+	globalMiddlewares       []MiddlewareFunc
+	positionalMiddlewares   bool
+	pathDecoding            PathDecoding
+	matcherMissStatus       int
 }
 
 var defaultConfig = &Config{
@@ -28,8 +38,39 @@ var defaultConfig = &Config{
 		behavior: behaviorSkip,
 		code:     0,
 	},
-	notFoundHandler:        http.NotFound,
-	handleMethodNotAllowed: false,
+	notFoundHandler:         http.NotFound,
+	methodNotAllowedHandler: nil,
+	errorHandler:            nil,
+	constraints:             nil,
+	stripHostPort:           false,
+	autoHead:                false,
+	autoOptionsHandler:      nil,
+	globalMiddlewares:       nil,
+	positionalMiddlewares:   false,
+	pathDecoding:            PathDecodingNone,
+	matcherMissStatus:       http.StatusNotFound,
+}
+
+// clone returns a deep copy of the Config, so that a Server is not affected by the changes to the Router's Config.
+func (c *Config) clone() *Config {
+	cp := *c
+
+	trailingSlashMatch, pathCorrectionMatch := *c.trailingSlashMatch, *c.pathCorrectionMatch
+	cp.trailingSlashMatch, cp.pathCorrectionMatch = &trailingSlashMatch, &pathCorrectionMatch
+
+	if c.constraints != nil {
+		cp.constraints = make(map[string]ConstraintFunc, len(c.constraints))
+		for name, f := range c.constraints {
+			cp.constraints[name] = f
+		}
+	}
+
+	if c.globalMiddlewares != nil {
+		cp.globalMiddlewares = make([]MiddlewareFunc, len(c.globalMiddlewares))
+		copy(cp.globalMiddlewares, c.globalMiddlewares)
+	}
+
+	return &cp
 }
 
 type group = Group
@@ -48,6 +89,12 @@ func New() *Router {
 				Core{
 					"",
 					&[]routeLog{},
+					&[]missLog{},
+					&scope{},
+					nil,
+					"",
+					"",
+					nil,
 					nil,
 				},
 			},
@@ -61,10 +108,20 @@ func New() *Router {
 					code:     defaultConfig.pathCorrectionMatch.code,
 				},
 				defaultConfig.notFoundHandler,
-				defaultConfig.handleMethodNotAllowed,
+				defaultConfig.methodNotAllowedHandler,
+				defaultConfig.errorHandler,
+				map[string]ConstraintFunc{},
+				defaultConfig.stripHostPort,
+				defaultConfig.autoHead,
+				defaultConfig.autoOptionsHandler,
+				defaultConfig.globalMiddlewares,
+				defaultConfig.positionalMiddlewares,
+				defaultConfig.pathDecoding,
+				defaultConfig.matcherMissStatus,
 			},
 		}
 
+	d.scope.config = d.config
 	return d
 }
 
@@ -80,10 +137,21 @@ func (r *Router) UsePathCorrectionMatch(opt ActionOption) {
 	opt.apply(r.config.pathCorrectionMatch)
 }
 
-// UseMethodNotAllowedHandler responds with HTTP status 405 and a list of registered HTTP methods for the path in the 'Allow' header
-// when a match has not been found but the path has been registered for other HTTP methods.
-func (r *Router) UseMethodNotAllowedHandler() {
-	r.config.handleMethodNotAllowed = true
+// UseMethodNotAllowedHandler registers the handler to execute when a match has not been found but the path has been
+// registered for other HTTP methods. The not found handler is not executed in that case.
+//
+// allowed lists the HTTP methods registered for the path, which is also set in the 'Allow' header before executing the handler.
+// If the handler is <nil>, DefaultMethodNotAllowedHandler is used.
+func (r *Router) UseMethodNotAllowedHandler(f func(w http.ResponseWriter, r *http.Request, allowed []string)) {
+	if f == nil {
+		f = DefaultMethodNotAllowedHandler
+	}
+	r.config.methodNotAllowedHandler = f
+}
+
+// DefaultMethodNotAllowedHandler replies to the request with HTTP status 405 (Method Not Allowed).
+func DefaultMethodNotAllowedHandler(w http.ResponseWriter, _ *http.Request, _ []string) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 }
 
 // UseNotFoundHandler registers the handler to execute when a route match is not found.
@@ -91,9 +159,129 @@ func (r *Router) UseNotFoundHandler(f func(w http.ResponseWriter, r *http.Reques
 	r.config.notFoundHandler = f
 }
 
+// UseAutoHead when enabled, serves HEAD requests using the GET route of the path when a HEAD route has not been registered.
+// The response body written by the GET route is discarded, while the 'Content-Length' header reports the length of the body
+// unless the handler has set it explicitly.
+func (r *Router) UseAutoHead() {
+	r.config.autoHead = true
+}
+
+// UseAutoOptions when enabled, replies to OPTIONS requests using the provided handler when an OPTIONS route has not been
+// registered for the path but the path has been registered for other HTTP methods.
+// The list of registered HTTP methods for the path is set in the 'Allow' header before executing the handler.
+//
+// If the handler is <nil>, it replies with HTTP status 204 (No Content).
+func (r *Router) UseAutoOptions(f func(w http.ResponseWriter, r *http.Request)) {
+	if f == nil {
+		f = defaultAutoOptionsHandler
+	}
+	r.config.autoOptionsHandler = f
+}
+
+// UseGlobal attaches middlewares which wrap the whole request dispatch, including the requests which didn't match a route
+// and the redirects performed by the trailing slash match and the path correction match.
+// Global middlewares are executed before the middlewares registered with Group.Use and Core.With.
+//
+// For the matched routes, global middlewares receive the matched Route. Otherwise, Route.Path is empty and
+// Route.Miss describes the kind of the miss.
+//
+//	router.UseGlobal(RequestID, AccessLog)
+//
+// Make sure to register global middlewares before calling Router.Serve().
+func (r *Router) UseGlobal(middlewares ...MiddlewareFunc) {
+	r.config.globalMiddlewares = append(r.config.globalMiddlewares, middlewares...)
+}
+
+// UsePositionalMiddlewares when enabled, applies the middlewares attached using Group.Use only to the routes
+// registered after calling Group.Use. By default, middlewares apply to all the routes registered within the Group.
+//
+// Make sure to enable it before calling Router.Serve().
+func (r *Router) UsePositionalMiddlewares() {
+	r.config.positionalMiddlewares = true
+}
+
+// UseErrorHandler registers the handler to execute when a request handler returns an error which reached the top of the
+// middleware stack. It is also executed for the errors returned by the handlers executed by the trailing slash match and
+// the path correction match.
+//
+// Use DefaultErrorHandler to reply with the status code and the public message of HTTPError errors.
+//
+// Make sure to register the error handler before calling Router.Serve().
+func (r *Router) UseErrorHandler(f ErrorHandlerFunc) {
+	r.config.errorHandler = f
+}
+
+// Host groups routes registered for the given host pattern.
+// The routes registered within the host group are matched only when the request's host matches the host pattern.
+// Requests whose host doesn't match any host pattern are routed to the routes registered without a host.
+//
+// A host pattern is a sequence of labels separated by dots. A label is either a literal or a param (:name)
+// which matches any non-empty label. Host params are accessible through Route.Params along with the path params.
+// Labels are matched case-insensitively, and host patterns without params take priority over host patterns with params.
+//
+//	router.Host("api.example.com", func(g *shift.Group) {
+//		g.GET("/users", ListUsers)
+//	})
+//
+//	router.Host(":tenant.example.com", func(g *shift.Group) {
+//		g.GET("/users/:id", GetUser) // route.Params.Get("tenant"), route.Params.Get("id")
+//	})
+//
+// The request's host is matched including the port. Use Router.UseStripHostPort to ignore the port.
+func (r *Router) Host(pattern string, fn func(g *Group)) {
+	scanHost(pattern)
+
+	stack := make([]MiddlewareFunc, len(r.mws), len(r.mws))
+	copy(stack, r.mws)
+
+	fn(&Group{Core{
+		logs:   r.logs,
+		misses: r.misses,
+		scope:  &scope{parent: r.scope},
+		mws:    stack,
+		host:   pattern,
+	}})
+}
+
+// UseStripHostPort when enabled, removes the port from the request's host before matching the host patterns.
+func (r *Router) UseStripHostPort() {
+	r.config.stripHostPort = true
+}
+
+// UseParamConstraint registers a typed param constraint which can be referred by the name within the route params.
+// It overrides the built-in typed constraint with the same name.
+//
+// Route params can be constrained using the :name<constraint> syntax. The constraint is either a typed constraint name
+// or a regular expression which must match the whole param value.
+//
+//	router.UseParamConstraint("slug", isSlug)
+//	router.GET("/posts/:id<int>", GetPostByID)  // Built-in typed constraint.
+//	router.GET("/posts/:slug<slug>", GetPostBySlug) // Custom typed constraint.
+//	router.GET("/files/:name<[a-z0-9_-]+>", GetFile) // Regular expression constraint.
+//
+// Built-in typed constraints are int, uint, alpha, alnum, uuid and date (YYYY-MM-DD).
+//
+// Make sure to register typed constraints before calling Router.Serve().
+func (r *Router) UseParamConstraint(name string, f ConstraintFunc) {
+	if name == "" {
+		panic("constraint name cannot be empty")
+	}
+
+	if f == nil {
+		panic("constraint func cannot be nil")
+	}
+
+	r.config.constraints[name] = f
+}
+
 type RouteInfo struct {
-	Method string
-	Path   string
+	Method      string
+	Path        string
+	Name        string
+	Host        string   // Host pattern of the route. Empty for routes registered without a host.
+	Middlewares []string // Function names of the resolved middleware stack of the route, including the global middlewares.
+	Meta        map[string]any
+	Tags        []string
 }
 
 // Routes returns all the registered routes.
@@ -101,26 +289,61 @@ type RouteInfo struct {
 func (r *Router) Routes() (routes []RouteInfo) {
 	routes = make([]RouteInfo, 0, len(*r.logs))
 
+	res := newMiddlewareResolver(r.config)
 	for _, log := range *r.logs {
-		routes = append(routes, RouteInfo{
-			Method: log.method,
-			Path:   log.path,
-		})
+		routes = appendRouteInfo(routes, log, res, r.config.globalMiddlewares)
 	}
 
 	return
 }
 
+// Remove removes the routes registered for the given method at the given path.
+// path is the full path of the route including the group bases. Use an empty method to remove routes registered with All.
+//
+// Returns false if no route was removed.
+// Removing a route doesn't affect the Servers already generated. Use DynamicServer to replace a running Server.
+func (r *Router) Remove(method string, path string) bool {
+	return r.RemoveHost("", method, path)
+}
+
+// RemoveHost removes the routes registered for the given host pattern and method at the given path.
+// See Router.Remove for details.
+func (r *Router) RemoveHost(host string, method string, path string) bool {
+	logs := (*r.logs)[:0]
+	removed := false
+
+	for _, log := range *r.logs {
+		if log.host == host && log.method == method && log.path == path {
+			removed = true
+			continue
+		}
+		logs = append(logs, log)
+	}
+
+	// Clear the dangling tail so that the removed handlers can be garbage collected.
+	for i := len(logs); i < len(*r.logs); i++ {
+		(*r.logs)[i] = routeLog{}
+	}
+
+	*r.logs = logs
+	return removed
+}
+
 type methodInfo struct {
 	staticRoutes int
 	logs         []routeInfo
 }
 
 type routeInfo struct {
-	method  string
-	path    string
-	handler HandlerFunc
-	static  bool
+	method   string
+	path     string
+	handler  HandlerFunc
+	static   bool
+	meta     *RouteMeta
+	matchers []Matcher
+	seq      int // Registration order of the route.
+
+	candidates []routeInfo // Routes merged into the route, selected by their matchers. See mergeConditionalRoutes.
 }
 
 var builtInMethods = []string{
@@ -136,27 +359,90 @@ var builtInMethods = []string{
 }
 
 // Serve generates the Server which implements http.Handler interface.
+// The Server is generated with a copy of the Router's configuration. Changes made to the Router afterwards apply to the
+// Servers generated later, e.g.: by DynamicServer.Reload.
 func (r *Router) Serve() *Server {
+	config := r.config.clone()
 	svr := &Server{
 		[9]multiplexer{},
 		nil,
 		nil,
-		r.config,
+		config,
+		namedRoutes(*r.logs),
+		r.Routes(),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 	}
 
-	byMethods := groupLogsByMethods(*r.logs)
+	res := newMiddlewareResolver(config)
+	svr.misses = newMissHandlers(*r.misses, "", config, res)
+
+	logs, byHosts, hosts := groupLogsByHosts(resolveLogs(*r.logs, res))
+
+	byMethods := groupLogsByMethods(logs)
 	svr.populateRoutes(byMethods)
+	svr.populatePreflightRoutes(logs)
+	svr.populateGlobalHandlers()
+
+	for _, host := range hosts {
+		hostSvr := &Server{
+			[9]multiplexer{},
+			nil,
+			nil,
+			config,
+			nil,
+			svr.routes, // Shares the route table, so that handlers executed by the host Server can describe the routes.
+			nil,
+			nil,
+			newMissHandlers(*r.misses, host, config, res),
+			nil,
+			nil,
+		}
+		hostSvr.populateRoutes(groupLogsByMethods(byHosts[host]))
+		hostSvr.populatePreflightRoutes(byHosts[host])
+		hostSvr.populateGlobalHandlers()
+
+		svr.hosts = append(svr.hosts, newHostRoutes(host, hostSvr))
+	}
+
+	// Static host patterns take priority over host patterns with params.
+	sort.SliceStable(svr.hosts, func(i, j int) bool {
+		return svr.hosts[i].isStatic() && !svr.hosts[j].isStatic()
+	})
 
 	return svr
 }
 
+// groupLogsByHosts separates the logs registered without a host from the logs registered for host patterns.
+// hosts contains the host patterns in the registration order.
+func groupLogsByHosts(all []routeLog) (logs []routeLog, byHosts map[string][]routeLog, hosts []string) {
+	byHosts = map[string][]routeLog{}
+
+	for _, log := range all {
+		if log.host == "" {
+			logs = append(logs, log)
+			continue
+		}
+
+		if _, ok := byHosts[log.host]; !ok {
+			hosts = append(hosts, log.host)
+		}
+		byHosts[log.host] = append(byHosts[log.host], log)
+	}
+
+	return
+}
+
 func groupLogsByMethods(logs []routeLog) (byMethods map[string]*methodInfo) {
 	byMethods = map[string]*methodInfo{}
-	var anyRoutes []routeLog
+	var anyRoutes []int
 
-	for _, log := range logs {
+	for i, log := range logs {
 		if log.method == "" {
-			anyRoutes = append(anyRoutes, log)
+			anyRoutes = append(anyRoutes, i)
 			continue
 		}
 
@@ -175,10 +461,13 @@ func groupLogsByMethods(logs []routeLog) (byMethods map[string]*methodInfo) {
 		}
 
 		info.logs = append(info.logs, routeInfo{
-			method:  log.method,
-			path:    log.path,
-			handler: log.handler,
-			static:  static,
+			method:   log.method,
+			path:     log.path,
+			handler:  log.handler,
+			static:   static,
+			meta:     log.meta,
+			matchers: log.matchers,
+			seq:      i,
 		})
 	}
 
@@ -193,15 +482,19 @@ func groupLogsByMethods(logs []routeLog) (byMethods map[string]*methodInfo) {
 			}
 		}
 
-		for _, route := range anyRoutes {
+		for _, i := range anyRoutes {
+			route := logs[i]
 			static := isStatic(route.path)
 
 			for method, info := range byMethods {
 				info.logs = append(info.logs, routeInfo{
-					method:  method,
-					path:    route.path,
-					handler: route.handler,
-					static:  static,
+					method:   method,
+					path:     route.path,
+					handler:  route.handler,
+					static:   static,
+					meta:     route.meta,
+					matchers: route.matchers,
+					seq:      i,
 				})
 
 				if static {
@@ -211,6 +504,16 @@ func groupLogsByMethods(logs []routeLog) (byMethods map[string]*methodInfo) {
 		}
 	}
 
+	for _, info := range byMethods {
+		info.logs = mergeConditionalRoutes(info.logs)
+		info.staticRoutes = 0
+		for _, route := range info.logs {
+			if route.static {
+				info.staticRoutes++
+			}
+		}
+	}
+
 	return
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -1161,7 +1162,7 @@ func TestRouter_PathCorrection_WithRedirectCustom(t *testing.T) {
 
 func TestRouter_MethodNotAllowed_On(t *testing.T) {
 	r := newTestRouter()
-	r.UseMethodNotAllowedHandler()
+	r.UseMethodNotAllowedHandler(nil)
 
 	r.GET("/foo/foo", fakeHandler())
 	r.POST("/foo/foo", fakeHandler())
@@ -1194,6 +1195,83 @@ func TestRouter_MethodNotAllowed_On(t *testing.T) {
 	}
 }
 
+func TestRouter_MethodNotAllowed_ShortCircuit(t *testing.T) {
+	r := New()
+	r.UseMethodNotAllowedHandler(nil)
+
+	notFound := false
+	r.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+		notFound = true
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	r.GET("/foo", fakeHandler())
+	r.POST("/foo", fakeHandler())
+	r.DELETE("/bar/:id", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{method: http.MethodPut, path: "/foo", code: http.StatusMethodNotAllowed, allow: "GET, POST"},
+		{method: http.MethodDelete, path: "/foo", code: http.StatusMethodNotAllowed, allow: "GET, POST"},
+		{method: "BAZ", path: "/foo", code: http.StatusMethodNotAllowed, allow: "GET, POST"},
+		{method: http.MethodGet, path: "/bar/1", code: http.StatusMethodNotAllowed, allow: "DELETE"},
+		{method: http.MethodGet, path: "/baz", code: http.StatusNotFound, allow: ""},
+		{method: "BAZ", path: "/baz", code: http.StatusNotFound, allow: ""},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path, func(t *testing.T) {
+			notFound = false
+
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, notFound == (tx.code == http.StatusNotFound), fmt.Sprintf("not found handler executed > expected: %v, got: %v", tx.code == http.StatusNotFound, notFound))
+
+			allow := rw.Header().Get("Allow")
+			assert(t, allow == tx.allow, fmt.Sprintf("allow header > expected: %s, got: %s", tx.allow, allow))
+		})
+	}
+}
+
+func TestRouter_MethodNotAllowed_CustomHandler(t *testing.T) {
+	r := New()
+
+	var allowed []string
+	r.UseMethodNotAllowedHandler(func(w http.ResponseWriter, r *http.Request, methods []string) {
+		allowed = methods
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = w.Write([]byte(`{"status":405}`))
+	})
+
+	r.GET("/foo", fakeHandler())
+	r.PATCH("/foo", fakeHandler())
+	r.Map([]string{"XYZ", "BAR"}, "/foo", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/foo", nil)
+	r.Serve().ServeHTTP(rw, req)
+
+	expected := []string{http.MethodGet, http.MethodPatch, "BAR", "XYZ"}
+	assert(t, len(allowed) == len(expected), fmt.Sprintf("allowed length > expected: %d, got: %d", len(expected), len(allowed)))
+	for i := range expected {
+		assert(t, allowed[i] == expected[i], fmt.Sprintf("allowed method > expected: %s, got: %s", expected[i], allowed[i]))
+	}
+
+	assert(t, rw.Code == http.StatusMethodNotAllowed, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusMethodNotAllowed, rw.Code))
+	assert(t, rw.Body.String() == `{"status":405}`, fmt.Sprintf("body > expected: %s, got: %s", `{"status":405}`, rw.Body.String()))
+	assert(t, rw.Header().Get("Allow") == "GET, PATCH, BAR, XYZ", fmt.Sprintf("allow header > expected: GET, PATCH, BAR, XYZ, got: %s", rw.Header().Get("Allow")))
+}
+
 func TestRouter_MethodNotAllowed_Off(t *testing.T) {
 	r := New()
 
@@ -1272,7 +1350,7 @@ func TestRouter_StaticRoutes_EmptyParams_ConcurrentAccess(t *testing.T) {
 			assert(t, b == nil, fmt.Sprintf("%s recovery > expected: no panic, got: %v", r.URL.String(), b))
 		}()
 
-		assert(t, route.Params.internal == nil, fmt.Sprintf("%s params internal > expected: %v, got: %p", r.URL.String(), nil, route.Params))
+		assert(t, route.Params.internal == nil, fmt.Sprintf("%s params internal > expected: %v, got: %p", r.URL.String(), nil, route.Params.internal))
 		paramAccessor(route.Params)
 		return nil
 	}
@@ -1649,6 +1727,7 @@ func TestRouter_ServeHTTP_MiddlewarePipeline_ExecuteOnlyOnRouteMatch(t *testing.
 
 func TestRouter_ServeHTTP_MiddlewarePipeline_IgnoreLateRegistered(t *testing.T) {
 	r := newTestRouter()
+	r.UsePositionalMiddlewares()
 
 	mw := func(name string) MiddlewareFunc {
 		return func(next HandlerFunc) HandlerFunc {
@@ -1709,6 +1788,98 @@ func TestRouter_ServeHTTP_MiddlewarePipeline_IgnoreLateRegistered(t *testing.T)
 	}
 }
 
+func TestRouter_ServeHTTP_MiddlewarePipeline_ScopeResolution(t *testing.T) {
+	r := newTestRouter()
+
+	mw := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request, route Route) error {
+				w.Write([]byte(name + "_"))
+				return next(w, r, route)
+			}
+		}
+	}
+
+	f := HandlerFunc(func(w http.ResponseWriter, r *http.Request, route Route) error {
+		w.Write([]byte(fmt.Sprintf("%s %s", r.Method, route.Path)))
+		return nil
+	})
+
+	r.Group("/movies", func(g *Group) {
+		g.Group("/drama", func(g *Group) {
+			g.GET("", f)
+			g.Use(mw("bar"))
+			g.With(mw("baz")).GET("/:id", f)
+		})
+		g.Use(mw("qux"))
+	})
+	r.GET("/healthz", f)
+	r.Group("/admin", func(g *Group) {
+		g.GET("/users", f)
+		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("not found"))
+		})
+		g.Use(mw("auth"))
+	})
+	r.Use(mw("foo"))
+
+	testTable := []struct {
+		path string
+		out  string
+	}{
+		{path: "/movies/drama", out: "foo_qux_bar_GET /movies/drama"},
+		{path: "/movies/drama/1", out: "foo_qux_bar_baz_GET /movies/drama/:id"},
+		{path: "/healthz", out: "foo_GET /healthz"},
+		{path: "/admin/users", out: "foo_auth_GET /admin/users"},
+		{path: "/admin/posts", out: "foo_auth_not found"},
+	}
+
+	srv := r.Serve()
+
+	for _, tx := range testTable {
+		t.Run(tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Body.String() == tx.out, fmt.Sprintf("expected: %s, got: %s", tx.out, rw.Body.String()))
+		})
+	}
+}
+
+func TestRouter_Routes_Middlewares(t *testing.T) {
+	r := New()
+	r.UseGlobal(globalTestMiddleware)
+	r.Group("/api", func(g *Group) {
+		g.GET("/users", fakeHandler())
+		g.With(RouteContext()).GET("/posts", fakeHandler())
+		g.Use(Recover())
+	})
+	r.GET("/healthz", fakeHandler())
+
+	expected := map[string][]string{
+		"/api/users": {"github.com/yousuf64/shift.globalTestMiddleware", "github.com/yousuf64/shift.RecoverWithWriter.func1"},
+		"/api/posts": {"github.com/yousuf64/shift.globalTestMiddleware", "github.com/yousuf64/shift.RecoverWithWriter.func1", "github.com/yousuf64/shift.RouteContext.func1"},
+		"/healthz":   {"github.com/yousuf64/shift.globalTestMiddleware"},
+	}
+
+	for _, route := range r.Routes() {
+		assert(t, reflect.DeepEqual(route.Middlewares, expected[route.Path]), fmt.Sprintf("%s > middlewares > expected: %v, got: %v", route.Path, expected[route.Path], route.Middlewares))
+	}
+
+	r.UsePositionalMiddlewares()
+	for _, route := range r.Routes() {
+		if route.Path == "/api/users" {
+			expected := []string{"github.com/yousuf64/shift.globalTestMiddleware"}
+			assert(t, reflect.DeepEqual(route.Middlewares, expected), fmt.Sprintf("%s > middlewares > expected: %v, got: %v", route.Path, expected, route.Middlewares))
+		}
+	}
+}
+
+func globalTestMiddleware(next HandlerFunc) HandlerFunc {
+	return next
+}
+
 func TestWithRedirectCustom(t *testing.T) {
 	t.Run("in 3XX", func(t *testing.T) {
 		statusCode := 333
@@ -1750,3 +1921,605 @@ func fakeHandler() HandlerFunc {
 		return nil
 	}
 }
+
+func TestRouter_ServeHTTP_ParamConstraints(t *testing.T) {
+	r := newTestRouter()
+	rec := &routeRecorder{}
+
+	r.UseParamConstraint("slug", func(value string) bool {
+		return strings.Contains(value, "-")
+	})
+
+	r.GET("/posts/:id<int>", rec.Handler())
+	r.GET("/posts/:slug<slug>", rec.Handler())
+	r.GET("/posts/:name", rec.Handler())
+	r.GET("/posts/:id<int>/comments", rec.Handler())
+	r.GET("/posts/:name/likes", rec.Handler())
+	r.GET("/files/:name<[a-z0-9_-]+>", rec.Handler())
+	r.GET("/files/*path", rec.Handler())
+	r.GET("/at/:date<date>", rec.Handler())
+	r.GET("/users/:id<uuid>/:tab<profile|settings>", rec.Handler())
+	r.GET("/users/:id<uuid>/*rest", rec.Handler())
+
+	tt := srvTestTable{
+		srvTestItem{method: http.MethodGet, path: "/posts/42", valid: true, pathTemplate: "/posts/:id<int>", params: map[string]string{"id": "42"}},
+		srvTestItem{method: http.MethodGet, path: "/posts/hello-world", valid: true, pathTemplate: "/posts/:slug<slug>", params: map[string]string{"slug": "hello-world"}},
+		srvTestItem{method: http.MethodGet, path: "/posts/hello", valid: true, pathTemplate: "/posts/:name", params: map[string]string{"name": "hello"}},
+		srvTestItem{method: http.MethodGet, path: "/posts/42/comments", valid: true, pathTemplate: "/posts/:id<int>/comments", params: map[string]string{"id": "42"}},
+		srvTestItem{method: http.MethodGet, path: "/posts/abc/comments", valid: false},
+		srvTestItem{method: http.MethodGet, path: "/posts/42/likes", valid: true, pathTemplate: "/posts/:name/likes", params: map[string]string{"name": "42"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report_2023", valid: true, pathTemplate: "/files/:name<[a-z0-9_-]+>", params: map[string]string{"name": "report_2023"}},
+		srvTestItem{method: http.MethodGet, path: "/files/Report.pdf", valid: true, pathTemplate: "/files/*path", params: map[string]string{"path": "Report.pdf"}},
+		srvTestItem{method: http.MethodGet, path: "/files/docs/report", valid: true, pathTemplate: "/files/*path", params: map[string]string{"path": "docs/report"}},
+		srvTestItem{method: http.MethodGet, path: "/at/2024-02-29", valid: true, pathTemplate: "/at/:date<date>", params: map[string]string{"date": "2024-02-29"}},
+		srvTestItem{method: http.MethodGet, path: "/at/2023-02-29", valid: false},
+		srvTestItem{method: http.MethodGet, path: "/at/today", valid: false},
+		srvTestItem{method: http.MethodGet, path: "/users/123e4567-e89b-12d3-a456-426614174000/profile", valid: true, pathTemplate: "/users/:id<uuid>/:tab<profile|settings>", params: map[string]string{"id": "123e4567-e89b-12d3-a456-426614174000", "tab": "profile"}},
+		srvTestItem{method: http.MethodGet, path: "/users/123e4567-e89b-12d3-a456-426614174000/orders", valid: true, pathTemplate: "/users/:id<uuid>/*rest", params: map[string]string{"id": "123e4567-e89b-12d3-a456-426614174000", "rest": "orders"}},
+		srvTestItem{method: http.MethodGet, path: "/users/42/profile", valid: false},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+}
+
+func TestRouter_PathCorrection_ParamConstraints(t *testing.T) {
+	r := newTestRouter()
+	r.UsePathCorrectionMatch(WithExecute())
+	rec := &routeRecorder{}
+
+	r.GET("/posts/:id<int>/comments", rec.Handler())
+	r.GET("/posts/:name/likes", rec.Handler())
+
+	tt := srvTestTable{
+		srvTestItem{method: http.MethodGet, path: "/POSTS/42/Comments", valid: true, pathTemplate: "/posts/:id<int>/comments", params: map[string]string{"id": "42"}},
+		srvTestItem{method: http.MethodGet, path: "/Posts/abc/comments", valid: false},
+		srvTestItem{method: http.MethodGet, path: "/posts/ABC/LIKES", valid: true, pathTemplate: "/posts/:name/likes", params: map[string]string{"name": "ABC"}},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+}
+
+func TestRouter_ServeHTTP_MultipleParamsPerSegment(t *testing.T) {
+	r := newTestRouter()
+	rec := &routeRecorder{}
+
+	r.GET("/files/:name.:ext", rec.Handler())
+	r.GET("/files/:name.:ext/raw", rec.Handler())
+	r.GET("/files/:name", rec.Handler())
+	r.GET("/flights/:from<[A-Z]{3}>-:to<[A-Z]{3}>", rec.Handler())
+	r.GET("/flights/:id", rec.Handler())
+	r.GET("/range/:from<int>-:to<int>", rec.Handler())
+	r.GET("/v:major.:minor/docs", rec.Handler())
+	r.GET("/tiles/:z/:x,:y.png", rec.Handler())
+
+	tt := srvTestTable{
+		srvTestItem{method: http.MethodGet, path: "/files/report.pdf", valid: true, pathTemplate: "/files/:name.:ext", params: map[string]string{"name": "report", "ext": "pdf"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report.2023.pdf", valid: true, pathTemplate: "/files/:name.:ext", params: map[string]string{"name": "report.2023", "ext": "pdf"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report.pdf/raw", valid: true, pathTemplate: "/files/:name.:ext/raw", params: map[string]string{"name": "report", "ext": "pdf"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report", valid: true, pathTemplate: "/files/:name", params: map[string]string{"name": "report"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report.", valid: true, pathTemplate: "/files/:name", params: map[string]string{"name": "report."}},
+		srvTestItem{method: http.MethodGet, path: "/files/.pdf", valid: true, pathTemplate: "/files/:name", params: map[string]string{"name": ".pdf"}},
+		srvTestItem{method: http.MethodGet, path: "/flights/LHR-JFK", valid: true, pathTemplate: "/flights/:from<[A-Z]{3}>-:to<[A-Z]{3}>", params: map[string]string{"from": "LHR", "to": "JFK"}},
+		srvTestItem{method: http.MethodGet, path: "/flights/lhr-jfk", valid: true, pathTemplate: "/flights/:id", params: map[string]string{"id": "lhr-jfk"}},
+		srvTestItem{method: http.MethodGet, path: "/range/10-20", valid: true, pathTemplate: "/range/:from<int>-:to<int>", params: map[string]string{"from": "10", "to": "20"}},
+		srvTestItem{method: http.MethodGet, path: "/range/10-", valid: false},
+		srvTestItem{method: http.MethodGet, path: "/range/a-20", valid: false},
+		srvTestItem{method: http.MethodGet, path: "/v1.2/docs", valid: true, pathTemplate: "/v:major.:minor/docs", params: map[string]string{"major": "1", "minor": "2"}},
+		srvTestItem{method: http.MethodGet, path: "/tiles/3/4,5.png", valid: true, pathTemplate: "/tiles/:z/:x,:y.png", params: map[string]string{"z": "3", "x": "4", "y": "5"}},
+		srvTestItem{method: http.MethodGet, path: "/tiles/3/4,5.jpg", valid: false},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+
+	t.Run("params order", func(t *testing.T) {
+		srv := r.Serve()
+		match, ok := srv.Lookup(http.MethodGet, "/tiles/3/4,5.png")
+		assert(t, ok, "expected a match")
+
+		keys := []string{}
+		match.Params.ForEach(func(k, v string) {
+			keys = append(keys, k)
+		})
+		assert(t, reflect.DeepEqual(keys, []string{"z", "x", "y"}), fmt.Sprintf("params order > expected: [z x y], got: %v", keys))
+	})
+}
+
+func TestRouter_ServeHTTP_DelimitedParamNames(t *testing.T) {
+	r := newTestRouter()
+	rec := &routeRecorder{}
+
+	// A segment having a single param keeps the delimiter chars in the param name.
+	r.GET("/users/:user-id", rec.Handler())
+	r.GET("/files/:file.name", rec.Handler())
+	r.GET("/files/:file.name/:size-:unit", rec.Handler())
+
+	tt := srvTestTable{
+		srvTestItem{method: http.MethodGet, path: "/users/42", valid: true, pathTemplate: "/users/:user-id", params: map[string]string{"user-id": "42"}},
+		srvTestItem{method: http.MethodGet, path: "/users/42-id", valid: true, pathTemplate: "/users/:user-id", params: map[string]string{"user-id": "42-id"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report", valid: true, pathTemplate: "/files/:file.name", params: map[string]string{"file.name": "report"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report.pdf", valid: true, pathTemplate: "/files/:file.name", params: map[string]string{"file.name": "report.pdf"}},
+		srvTestItem{method: http.MethodGet, path: "/files/report.pdf/10-kb", valid: true, pathTemplate: "/files/:file.name/:size-:unit", params: map[string]string{"file.name": "report.pdf", "size": "10", "unit": "kb"}},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+}
+
+func TestRouter_ServeHTTP_OptionalSegments(t *testing.T) {
+	r := newTestRouter()
+	rec := &routeRecorder{}
+
+	r.GET("/archive/:year<int>/:month<int>?/:day<int>?", rec.Handler())
+	r.GET("/docs/:lang?", rec.Handler())
+	r.GET("/docs/search", rec.Handler())
+
+	tt := srvTestTable{
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/archive/2024", valid: true, pathTemplate: "/archive/:year<int>/:month<int>?/:day<int>?", params: map[string]string{"year": "2024"}, paramsCount: 1},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/archive/2024/02", valid: true, pathTemplate: "/archive/:year<int>/:month<int>?/:day<int>?", params: map[string]string{"year": "2024", "month": "02"}, paramsCount: 2},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/archive/2024/02/29", valid: true, pathTemplate: "/archive/:year<int>/:month<int>?/:day<int>?", params: map[string]string{"year": "2024", "month": "02", "day": "29"}, paramsCount: 3},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/archive/2024/feb", valid: false},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/archive", valid: false},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/docs", valid: true, pathTemplate: "/docs/:lang?", paramsCount: 0},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/docs/en", valid: true, pathTemplate: "/docs/:lang?", params: map[string]string{"lang": "en"}, paramsCount: 1},
+		srvTestItemWithParamsCount{method: http.MethodGet, path: "/docs/search", valid: true, pathTemplate: "/docs/search", paramsCount: 0},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+
+	t.Run("missing optional param", func(t *testing.T) {
+		srv := r.Serve()
+		match, ok := srv.Lookup(http.MethodGet, "/archive/2024")
+		assert(t, ok, "expected a match")
+
+		_, ok = match.Params.Lookup("month")
+		assert(t, !ok, "expected param 'month' to be missing")
+	})
+
+	t.Run("duplicate route", func(t *testing.T) {
+		r := newTestRouter()
+		r.GET("/blog/:id", fakeHandler())
+		pnk := panicHandler(func() {
+			r.GET("/blog/:id/:slug?", fakeHandler())
+			r.Serve()
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+}
+
+func TestRouter_OptionalSegments_StaticConflict(t *testing.T) {
+	tt := []struct {
+		name    string
+		dynamic []string // Extra routes to select the mux variant.
+	}{
+		{name: "hybrid"},
+		{name: "radix", dynamic: []string{"/a/:x", "/b/:x", "/c/:x"}},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			for _, paths := range [][]string{
+				{"/archive/:year?", "/archive"},
+				{"/archive", "/archive/:year?"},
+			} {
+				r := newTestRouter()
+				for _, path := range tx.dynamic {
+					r.GET(path, fakeHandler())
+				}
+				r.GET(paths[0], fakeHandler())
+				r.GET(paths[1], fakeHandler())
+
+				expected := fmt.Sprintf("%s conflicts with already registered route %s", paths[1], paths[0])
+				pnk := panicHandler(func() { r.Serve() })
+				assert(t, pnk == expected, fmt.Sprintf("panic > expected: %s, got: %v", expected, pnk))
+			}
+		})
+	}
+
+	t.Run("mux variant", func(t *testing.T) {
+		r := newTestRouter()
+		r.GET("/archive/:year?", fakeHandler())
+		r.GET("/about", fakeHandler())
+		assert(t, reflect.TypeOf(r.Serve().muxes[0]) == reflect.TypeOf(&hybridMux{}), "expected a hybrid mux")
+
+		r.GET("/a/:x", fakeHandler())
+		r.GET("/b/:x", fakeHandler())
+		r.GET("/c/:x", fakeHandler())
+		assert(t, reflect.TypeOf(r.Serve().muxes[0]) == reflect.TypeOf(&radixMux{}), "expected a radix mux")
+	})
+}
+
+func TestRouter_PathCorrection_MultipleParamsPerSegment(t *testing.T) {
+	r := newTestRouter()
+	r.UsePathCorrectionMatch(WithExecute())
+	rec := &routeRecorder{}
+
+	r.GET("/files/:name.:ext/raw", rec.Handler())
+	r.GET("/flights/:from<[A-Z]{3}>-:to<[A-Z]{3}>", rec.Handler())
+	r.GET("/archive/:year/:month?", rec.Handler())
+
+	tt := srvTestTable{
+		srvTestItem{method: http.MethodGet, path: "/FILES/Report.PDF/Raw", valid: true, pathTemplate: "/files/:name.:ext/raw", params: map[string]string{"name": "Report", "ext": "PDF"}},
+		srvTestItem{method: http.MethodGet, path: "/Flights/LHR-JFK", valid: true, pathTemplate: "/flights/:from<[A-Z]{3}>-:to<[A-Z]{3}>", params: map[string]string{"from": "LHR", "to": "JFK"}},
+		srvTestItem{method: http.MethodGet, path: "/ARCHIVE//2024", valid: true, pathTemplate: "/archive/:year/:month?", params: map[string]string{"year": "2024"}},
+		srvTestItem{method: http.MethodGet, path: "/Archive/2024/02", valid: true, pathTemplate: "/archive/:year/:month?", params: map[string]string{"year": "2024", "month": "02"}},
+	}
+
+	testRouter(t, r.Serve(), rec, tt)
+}
+
+func TestServer_Lookup(t *testing.T) {
+	r := newTestRouter()
+	r.UseTrailingSlashMatch(WithRedirect())
+	r.UsePathCorrectionMatch(WithExecute())
+
+	executed := false
+	h := func(w http.ResponseWriter, r *http.Request, route Route) error {
+		executed = true
+		return nil
+	}
+
+	r.GET("/users", h)
+	r.GET("/users/:id", h)
+	r.GET("/users/:id/posts/", h)
+	r.GET("/files/*path", h)
+	r.Map([]string{"FOO"}, "/foo/:bar", h)
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method      string
+		path        string
+		found       bool
+		template    string
+		kind        MatchKind
+		matchedPath string
+		params      map[string]string
+	}{
+		{method: http.MethodGet, path: "/users", found: true, template: "/users", kind: MatchExact, matchedPath: "/users"},
+		{method: http.MethodGet, path: "/users/42", found: true, template: "/users/:id", kind: MatchExact, matchedPath: "/users/42", params: map[string]string{"id": "42"}},
+		{method: http.MethodGet, path: "/users/42/posts", found: true, template: "/users/:id/posts/", kind: MatchTrailingSlash, matchedPath: "/users/42/posts/", params: map[string]string{"id": "42"}},
+		{method: http.MethodGet, path: "/USERS//42/Posts/", found: true, template: "/users/:id/posts/", kind: MatchPathCorrection, matchedPath: "/users/42/posts/", params: map[string]string{"id": "42"}},
+		{method: http.MethodGet, path: "/files/a/b.png", found: true, template: "/files/*path", kind: MatchExact, matchedPath: "/files/a/b.png", params: map[string]string{"path": "a/b.png"}},
+		{method: "FOO", path: "/foo/baz", found: true, template: "/foo/:bar", kind: MatchExact, matchedPath: "/foo/baz", params: map[string]string{"bar": "baz"}},
+		{method: http.MethodGet, path: "/posts", found: false},
+		{method: http.MethodPost, path: "/users", found: false},
+		{method: "BAR", path: "/foo/baz", found: false},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path, func(t *testing.T) {
+			match, ok := srv.Lookup(tx.method, tx.path)
+			assert(t, ok == tx.found, fmt.Sprintf("found > expected: %v, got: %v", tx.found, ok))
+			if !tx.found {
+				return
+			}
+
+			assert(t, match.Method == tx.method, fmt.Sprintf("method > expected: %s, got: %s", tx.method, match.Method))
+			assert(t, match.Path == tx.template, fmt.Sprintf("template > expected: %s, got: %s", tx.template, match.Path))
+			assert(t, match.Kind == tx.kind, fmt.Sprintf("kind > expected: %s, got: %s", tx.kind, match.Kind))
+			assert(t, match.MatchedPath == tx.matchedPath, fmt.Sprintf("matched path > expected: %s, got: %s", tx.matchedPath, match.MatchedPath))
+			assert(t, match.Params.Len() == len(tx.params), fmt.Sprintf("params count > expected: %d, got: %d", len(tx.params), match.Params.Len()))
+			for k, v := range tx.params {
+				actual := match.Params.Get(k)
+				assert(t, actual == v, fmt.Sprintf("param '%s' > expected: %s, got: %s", k, v, actual))
+			}
+		})
+	}
+
+	assert(t, !executed, "expected lookup not to execute the request handler")
+
+	t.Run("params survive subsequent lookups", func(t *testing.T) {
+		m1, _ := srv.Lookup(http.MethodGet, "/users/1")
+		m2, _ := srv.Lookup(http.MethodGet, "/users/2")
+		assert(t, m1.Params.Get("id") == "1", fmt.Sprintf("param 'id' > expected: 1, got: %s", m1.Params.Get("id")))
+		assert(t, m2.Params.Get("id") == "2", fmt.Sprintf("param 'id' > expected: 2, got: %s", m2.Params.Get("id")))
+	})
+}
+
+func TestRouter_AutoHead(t *testing.T) {
+	r := New()
+	r.UseAutoHead()
+
+	r.GET("/foo", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		w.Header().Set("X-Route", route.Path)
+		_, _ = w.Write([]byte("hello, world"))
+		return nil
+	})
+	r.GET("/users/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(route.Params.Get("id")))
+		return nil
+	})
+	r.GET("/explicit", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		w.Header().Set("Content-Length", "100")
+		return nil
+	})
+	r.HEAD("/users/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		w.Header().Set("X-Head", "true")
+		return nil
+	})
+	r.POST("/bar", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		path          string
+		code          int
+		contentLength string
+		header        string
+		value         string
+	}{
+		{path: "/foo", code: http.StatusOK, contentLength: "12", header: "X-Route", value: "/foo"},
+		{path: "/users/1", code: http.StatusOK, contentLength: "", header: "X-Head", value: "true"},
+		{path: "/explicit", code: http.StatusOK, contentLength: "100"},
+		{path: "/bar", code: http.StatusNotFound},
+		{path: "/baz", code: http.StatusNotFound},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodHead, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.code != http.StatusOK {
+				return
+			}
+
+			assert(t, rw.Body.Len() == 0, fmt.Sprintf("body > expected: empty, got: %s", rw.Body.String()))
+			cl := rw.Header().Get("Content-Length")
+			assert(t, cl == tx.contentLength, fmt.Sprintf("content length > expected: %s, got: %s", tx.contentLength, cl))
+			if tx.header != "" {
+				v := rw.Header().Get(tx.header)
+				assert(t, v == tx.value, fmt.Sprintf("header '%s' > expected: %s, got: %s", tx.header, tx.value, v))
+			}
+		})
+	}
+
+	t.Run("status code", func(t *testing.T) {
+		r := New()
+		r.UseAutoHead()
+		r.GET("/users/:id", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(route.Params.Get("id")))
+			return nil
+		})
+
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodHead, "/users/123", nil)
+		r.Serve().ServeHTTP(rw, req)
+
+		assert(t, rw.Code == http.StatusAccepted, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusAccepted, rw.Code))
+		assert(t, rw.Header().Get("Content-Length") == "3", fmt.Sprintf("content length > expected: 3, got: %s", rw.Header().Get("Content-Length")))
+		assert(t, rw.Body.Len() == 0, fmt.Sprintf("body > expected: empty, got: %s", rw.Body.String()))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		r := New()
+		r.UseAutoHead()
+		r.GET("/stream", func(w http.ResponseWriter, r *http.Request, route Route) error {
+			f, ok := w.(http.Flusher)
+			if !ok {
+				w.WriteHeader(http.StatusTeapot)
+				return nil
+			}
+
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("chunk"))
+			f.Flush()
+			_, _ = w.Write([]byte("chunk"))
+			return nil
+		})
+
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodHead, "/stream", nil)
+		r.Serve().ServeHTTP(rw, req)
+
+		assert(t, rw.Flushed, "expected the response to be flushed")
+		assert(t, rw.Code == http.StatusPartialContent, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusPartialContent, rw.Code))
+		assert(t, rw.Header().Get("Content-Length") == "", fmt.Sprintf("content length > expected: empty, got: %s", rw.Header().Get("Content-Length")))
+		assert(t, rw.Body.Len() == 0, fmt.Sprintf("body > expected: empty, got: %s", rw.Body.String()))
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		match, ok := srv.Lookup(http.MethodHead, "/foo")
+		assert(t, ok, "expected a match")
+		assert(t, match.Method == http.MethodGet, fmt.Sprintf("method > expected: %s, got: %s", http.MethodGet, match.Method))
+		assert(t, match.Path == "/foo", fmt.Sprintf("template > expected: /foo, got: %s", match.Path))
+	})
+}
+
+func TestRouter_AutoHead_Off(t *testing.T) {
+	r := New()
+	r.GET("/foo", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodHead, "/foo", nil)
+	r.Serve().ServeHTTP(rw, req)
+
+	assert(t, rw.Code == http.StatusNotFound, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusNotFound, rw.Code))
+}
+
+func TestRouter_AutoOptions(t *testing.T) {
+	r := New()
+	r.UseAutoHead()
+	r.UseAutoOptions(nil)
+
+	r.GET("/foo", fakeHandler())
+	r.POST("/foo", fakeHandler())
+	r.Map([]string{"BAR"}, "/foo", fakeHandler())
+	r.PUT("/users/:id", fakeHandler())
+	r.OPTIONS("/explicit", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		w.WriteHeader(http.StatusTeapot)
+		return nil
+	})
+	r.GET("/explicit", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		path  string
+		code  int
+		allow string
+	}{
+		{path: "/foo", code: http.StatusNoContent, allow: "GET, POST, HEAD, BAR, OPTIONS"},
+		{path: "/users/1", code: http.StatusNoContent, allow: "PUT, OPTIONS"},
+		{path: "/explicit", code: http.StatusTeapot},
+		{path: "/baz", code: http.StatusNotFound},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodOptions, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			allow := rw.Header().Get("Allow")
+			assert(t, allow == tx.allow, fmt.Sprintf("allow header > expected: %s, got: %s", tx.allow, allow))
+		})
+	}
+
+	t.Run("custom handler", func(t *testing.T) {
+		r := New()
+		r.UseAutoOptions(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Methods", w.Header().Get("Allow"))
+			w.WriteHeader(http.StatusOK)
+		})
+		r.DELETE("/foo", fakeHandler())
+
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, "/foo", nil)
+		r.Serve().ServeHTTP(rw, req)
+
+		assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+		methods := rw.Header().Get("Access-Control-Allow-Methods")
+		assert(t, methods == "DELETE, OPTIONS", fmt.Sprintf("allow methods > expected: DELETE, OPTIONS, got: %s", methods))
+	})
+
+	t.Run("method not allowed lists OPTIONS", func(t *testing.T) {
+		r := New()
+		r.UseMethodNotAllowedHandler(nil)
+		r.UseAutoOptions(nil)
+		r.GET("/foo", fakeHandler())
+
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/foo", nil)
+		r.Serve().ServeHTTP(rw, req)
+
+		allow := rw.Header().Get("Allow")
+		assert(t, allow == "GET, OPTIONS", fmt.Sprintf("allow header > expected: GET, OPTIONS, got: %s", allow))
+	})
+}
+
+func TestRouter_UseGlobal(t *testing.T) {
+	type record struct {
+		path string
+		miss MissKind
+	}
+
+	var records []record
+	var order []string
+
+	r := New()
+	r.UseTrailingSlashMatch(WithRedirect())
+	r.UsePathCorrectionMatch(WithRedirectCustom(http.StatusTemporaryRedirect))
+	r.UseMethodNotAllowedHandler(nil)
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			records = append(records, record{route.Path, route.Miss})
+			order = append(order, "global")
+			w.Header().Set("X-Request-Id", "123")
+			return next(w, r, route)
+		}
+	})
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			order = append(order, "router")
+			return next(w, r, route)
+		}
+	})
+
+	r.GET("/users/:id", fakeHandler())
+	r.POST("/foo", fakeHandler())
+
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		code   int
+		record record
+		order  []string
+	}{
+		{method: http.MethodGet, path: "/users/1", code: http.StatusOK, record: record{"/users/:id", MissNone}, order: []string{"global", "router"}},
+		{method: http.MethodGet, path: "/bar", code: http.StatusNotFound, record: record{"", MissNotFound}, order: []string{"global"}},
+		{method: "XYZ", path: "/bar", code: http.StatusNotFound, record: record{"", MissNotFound}, order: []string{"global"}},
+		{method: http.MethodGet, path: "/foo", code: http.StatusMethodNotAllowed, record: record{"", MissMethodNotAllowed}, order: []string{"global"}},
+		{method: http.MethodPost, path: "/foo/", code: http.StatusMovedPermanently, record: record{"", MissTrailingSlashRedirect}, order: []string{"global"}},
+		{method: http.MethodGet, path: "/USERS/1", code: http.StatusTemporaryRedirect, record: record{"", MissPathCorrectionRedirect}, order: []string{"global"}},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path, func(t *testing.T) {
+			records, order = nil, nil
+
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest(tx.method, tx.path, nil)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, rw.Header().Get("X-Request-Id") == "123", "expected global middleware to set the header")
+			assert(t, len(records) == 1, fmt.Sprintf("global middleware executions > expected: 1, got: %d", len(records)))
+			assert(t, records[0] == tx.record, fmt.Sprintf("route > expected: %+v, got: %+v", tx.record, records[0]))
+			assert(t, fmt.Sprint(order) == fmt.Sprint(tx.order), fmt.Sprintf("order > expected: %v, got: %v", tx.order, order))
+		})
+	}
+}
+
+func TestRouter_UseGlobal_AutoOptions(t *testing.T) {
+	r := New()
+	r.UseAutoOptions(nil)
+
+	var miss MissKind
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			miss = route.Miss
+			return next(w, r, route)
+		}
+	})
+	r.GET("/foo", fakeHandler())
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodOptions, "/foo", nil)
+	r.Serve().ServeHTTP(rw, req)
+
+	assert(t, rw.Code == http.StatusNoContent, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusNoContent, rw.Code))
+	assert(t, miss == MissAutoOptions, fmt.Sprintf("miss > expected: %s, got: %s", MissAutoOptions, miss))
+}
+
+func TestRouter_UseGlobal_ShortCircuit(t *testing.T) {
+	r := New()
+	r.UseGlobal(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, route Route) error {
+			if route.Miss == MissNotFound {
+				w.WriteHeader(http.StatusGone)
+				return nil
+			}
+			return next(w, r, route)
+		}
+	})
+	r.GET("/foo", fakeHandler())
+
+	srv := r.Serve()
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/bar", nil)
+	srv.ServeHTTP(rw, req)
+	assert(t, rw.Code == http.StatusGone, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusGone, rw.Code))
+
+	rw = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/foo", nil)
+	srv.ServeHTTP(rw, req)
+	assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+}
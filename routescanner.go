@@ -1,16 +1,23 @@
 package shift
 
+import "strings"
+
+// routeScanner splits a route path into static segments, param segments and wildcard segments.
+//
+//	e.g.:
+//	/files/:name.:ext<[a-z]+>/*rest -> /files/, :name, ., :ext<[a-z]+>, /, *rest
+//
+// A param segment ends after the param name, the param constraint and the optional marker (?).
+// A wildcard segment ends at the next slash.
 type routeScanner struct {
 	path string
 	low  int
 	high int
-	wc   bool
 }
 
 func newRouteScanner(path string) *routeScanner {
 	return &routeScanner{
 		path: path,
-		wc:   len(path) > 0 && (path[0] == ':' || path[0] == '*'),
 	}
 }
 
@@ -19,26 +26,105 @@ func (r *routeScanner) next() string {
 		return ""
 	}
 
-Loop:
-	for r.high < len(r.path) {
-		if r.wc {
-			if r.path[r.high] == '/' {
-				break Loop
-			}
-		} else {
+	switch r.path[r.high] {
+	case ':':
+		r.high = paramEnd(r.path, r.high)
+	case '*':
+		for r.high < len(r.path) && r.path[r.high] != '/' {
+			r.high++
+		}
+	default:
+	Loop:
+		for r.high < len(r.path) {
 			switch r.path[r.high] {
 			case ':', '*':
 				break Loop
 			}
+			r.high++
 		}
-
-		r.high++
 	}
 
-	r.wc = !r.wc
 	seg := r.path[r.low:r.high]
-
 	r.low = r.high
 
 	return seg
 }
+
+// paramEnd returns the index following the param segment starting at the index i.
+// It doesn't validate the param segment. See scanPath.
+func paramEnd(path string, i int) int {
+	i = paramNameEnd(path, i+1) // Skip ':' prefix.
+
+	if i < len(path) && path[i] == '<' {
+		depth := 0
+	Constraint:
+		for ; i < len(path); i++ {
+			switch path[i] {
+			case '<':
+				depth++
+			case '>':
+				depth--
+				if depth == 0 {
+					i++
+					break Constraint
+				}
+			case '/':
+				break Constraint
+			}
+		}
+	}
+
+	if i < len(path) && path[i] == '?' {
+		i++
+	}
+
+	return i
+}
+
+// paramNameEnd returns the index following the param name starting at the index i.
+//
+// The param name spans up to the constraint, the optional marker (?) or the end of the segment. e.g.: /:user-id, /:file.name
+// When the segment has multiple params, chars other than the param name chars terminate the param name instead.
+// Thus, they can delimit the params. e.g.: /:from-:to, /:name.:ext
+func paramNameEnd(path string, i int) int {
+	multi := isMultiParamSegment(path, strings.LastIndexByte(path[:i], '/')+1)
+	for ; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '/', '<', '?', ':', '*':
+			return i
+		default:
+			if multi && !isParamNameChar(c) {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// isMultiParamSegment reports whether the segment starting at the index i has more than one param.
+// Constraints are skipped, since they can contain ':' chars. e.g.: :t<[0-9:]+>
+func isMultiParamSegment(path string, i int) bool {
+	depth := 0
+	params := 0
+	for ; i < len(path) && path[i] != '/'; i++ {
+		switch path[i] {
+		case '<':
+			depth++
+		case '>':
+			if depth > 0 {
+				depth--
+			}
+		case ':':
+			if depth == 0 {
+				params++
+			}
+		}
+	}
+	return params > 1
+}
+
+// isParamNameChar reports whether the char can be a part of a param name within a segment having multiple params.
+// Other chars terminate the param name. Thus, they can delimit the params within the same segment. e.g.: /:from-:to
+func isParamNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
@@ -11,6 +11,12 @@ func TestRouteScanner(t *testing.T) {
 		{path: "/users/:id/action", segments: []string{"/users/", ":id", "/action"}},
 		{path: "/assets/*dir", segments: []string{"/assets/", "*dir"}},
 		{path: "/heroes/:name/:power", segments: []string{"/heroes/", ":name", "/", ":power"}},
+		{path: "/files/:name.:ext", segments: []string{"/files/", ":name", ".", ":ext"}},
+		{path: "/flights/:from<[A-Z]{3}>-:to<[A-Z]{3}>", segments: []string{"/flights/", ":from<[A-Z]{3}>", "-", ":to<[A-Z]{3}>"}},
+		{path: "/archive/:year/:month?", segments: []string{"/archive/", ":year", "/", ":month?"}},
+		{path: "/users/:user-id/posts", segments: []string{"/users/", ":user-id", "/posts"}},
+		{path: "/files/:file.name<[a-z.]+>", segments: []string{"/files/", ":file.name<[a-z.]+>"}},
+		{path: "/tiles/:x,:y.png", segments: []string{"/tiles/", ":x", ",", ":y", ".png"}},
 	}
 
 	for _, item := range table {
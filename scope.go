@@ -0,0 +1,122 @@
+package shift
+
+import (
+	"reflect"
+	"runtime"
+)
+
+// scope is a node of the middleware scope tree.
+// The Router owns the root scope, while each Group and each Core.With call creates a child scope.
+// Middlewares attached to a scope apply to all the routes registered within the scope and its descendants,
+// regardless of the registration order.
+type scope struct {
+	parent *scope
+	mws    []MiddlewareFunc
+	config *Config // Set on the root scope only.
+}
+
+// root returns the root scope of the scope tree.
+func (s *scope) root() *scope {
+	for s.parent != nil {
+		s = s.parent
+	}
+	return s
+}
+
+// middlewareResolver resolves the middleware stacks of the routes.
+// Stacks resolved from the scope tree are cached per scope.
+type middlewareResolver struct {
+	positional bool
+	stacks     map[*scope][]MiddlewareFunc
+}
+
+func newMiddlewareResolver(config *Config) *middlewareResolver {
+	return &middlewareResolver{
+		positional: config.positionalMiddlewares,
+		stacks:     map[*scope][]MiddlewareFunc{},
+	}
+}
+
+// resolve returns the middleware stack of a route registered within the scope.
+// snapshot is the middleware stack at the time of the registration, which is used with the positional semantics.
+func (res *middlewareResolver) resolve(s *scope, snapshot []MiddlewareFunc) []MiddlewareFunc {
+	if res.positional {
+		return snapshot
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	if stack, ok := res.stacks[s]; ok {
+		return stack
+	}
+
+	parent := res.resolve(s.parent, nil)
+	stack := make([]MiddlewareFunc, 0, len(parent)+len(s.mws))
+	stack = append(stack, parent...)
+	stack = append(stack, s.mws...)
+
+	res.stacks[s] = stack
+	return stack
+}
+
+// chain chains the handler with the middleware stack.
+func chain(mws []MiddlewareFunc, handler HandlerFunc) HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// middlewareNames returns the function names of the middlewares.
+// Middlewares returned by factory functions are named after the enclosing function. e.g.: github.com/yousuf64/shift.CORS.func1
+func middlewareNames(mws []MiddlewareFunc) []string {
+	if len(mws) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(mws))
+	for i, mw := range mws {
+		if f := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()); f != nil {
+			names[i] = f.Name()
+		}
+	}
+	return names
+}
+
+// resolveLogs returns copies of the logs whose handlers are chained with the resolved middleware stacks.
+// The handlers recover the panics of the MustX accessors of Params before reaching the middlewares.
+func resolveLogs(logs []routeLog, res *middlewareResolver) []routeLog {
+	resolved := make([]routeLog, len(logs))
+	for i, log := range logs {
+		log.mws = res.resolve(log.scope, log.mws)
+		log.handler = chain(log.mws, recoverMustParam(log.handler))
+		resolved[i] = log
+	}
+	return resolved
+}
+
+// appendRouteInfo appends the route information of the log to the routes.
+// The routes of a mounted Router are appended in place of the log, prefixed by the middlewares of the log.
+func appendRouteInfo(routes []RouteInfo, log routeLog, res *middlewareResolver, global []MiddlewareFunc) []RouteInfo {
+	names := middlewareNames(append(global[:len(global):len(global)], res.resolve(log.scope, log.mws)...))
+
+	if log.mounted != nil {
+		for _, route := range log.mounted {
+			route.Middlewares = append(names[:len(names):len(names)], route.Middlewares...)
+			routes = append(routes, route)
+		}
+		return routes
+	}
+
+	return append(routes, RouteInfo{
+		Method:      log.method,
+		Path:        log.path,
+		Name:        log.name,
+		Host:        log.host,
+		Middlewares: names,
+		Meta:        log.meta.Map(),
+		Tags:        log.meta.Tags(),
+	})
+}
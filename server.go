@@ -1,6 +1,7 @@
 package shift
 
 import (
+	"fmt"
 	"net/http"
 	"sort"
 	"strings"
@@ -11,37 +12,229 @@ type Server struct {
 	muxIndices  []int                  // Indices of non-nil muxes. This index is useful to skip <nil> muxes.
 	customMuxes map[string]multiplexer // Muxes for custom HTTP methods.
 	config      *Config
+	names       map[string]string      // Route name -> route path.
+	routes      []RouteInfo            // Routes the Server was generated with.
+	hosts       []*hostRoutes          // Routes registered for host patterns. Static host patterns are placed first.
+	preflight   map[string]multiplexer // Method -> routes executing CORS preflight requests through the route's middleware stack.
+	misses      *missHandlers          // Group-scoped not found handlers and method not allowed handlers.
+	miss        HandlerFunc            // Replies to the requests which didn't match a route through the global middlewares.
+	redirect    HandlerFunc            // Redirects the requests through the global middlewares.
 }
 
 func (svr *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.RawPath
-	if path == "" {
-		path = r.URL.Path
+	if len(svr.hosts) > 0 {
+		host := r.Host
+		if svr.config.stripHostPort {
+			host = stripPort(host)
+		}
+
+		for _, h := range svr.hosts {
+			if h.match(host) {
+				hostPs := h.params(host)
+				h.svr.serve(w, r, hostPs)
+				if hostPs != nil {
+					h.release(hostPs)
+				}
+				return
+			}
+		}
 	}
 
-	var mux multiplexer
-	if idx := methodIndex(r.Method); idx >= 0 {
-		mux = svr.muxes[idx]
-	} else {
-		mux = svr.customMuxes[r.Method]
+	// Fallback to the routes registered without a host.
+	svr.serve(w, r, nil)
+}
+
+// serve dispatches the request to the matching route of the Server.
+// hostPs holds the params captured from the host pattern if any.
+func (svr *Server) serve(w http.ResponseWriter, r *http.Request, hostPs *internalParams) {
+	path, ok := svr.config.matchPath(r)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if mux := svr.mux(r.Method); mux != nil && svr.dispatch(w, r, mux, path, hostPs) {
+		return
+	}
+
+	// Fallback to the GET route for HEAD requests.
+	if r.Method == http.MethodHead && svr.config.autoHead && svr.muxes[0] != nil {
+		hw := &headResponseWriter{ResponseWriter: w}
+		if svr.dispatch(hw, r, svr.muxes[0], path, hostPs) {
+			hw.flush()
+			return
+		}
+	}
+
+	// Execute CORS preflight requests through the middleware stack of the requested method's route.
+	if r.Method == http.MethodOptions && svr.preflight != nil && svr.dispatchPreflight(w, r, path, hostPs) {
+		return
+	}
+
+	svr.serveMiss(w, r, path, hostPs)
+}
+
+// serveMiss replies to the request which didn't match a route, executing the global middlewares if any.
+func (svr *Server) serveMiss(w http.ResponseWriter, r *http.Request, path string, hostPs *internalParams) {
+	allowed := svr.missAllowed(path, r.Method)
+	if svr.miss != nil {
+		_ = svr.miss(w, r, Route{
+			Params:  Params{nil, hostPs, false},
+			Miss:    svr.missKind(r.Method, allowed),
+			svr:     svr,
+			allowed: allowedRef(allowed),
+		})
+		return
+	}
+
+	svr.handleMiss(w, r, path, hostPs, allowed)
+}
+
+// missAllowed returns the allowed methods of the path if the miss is replied with them, either by the automatic
+// OPTIONS handler or a method not allowed handler. Otherwise, returns <nil> without looking up the muxes.
+func (svr *Server) missAllowed(path string, method string) []string {
+	if (method == http.MethodOptions && svr.config.autoOptionsHandler != nil) ||
+		svr.config.methodNotAllowedHandler != nil ||
+		(svr.misses != nil && svr.misses.methodNotAllowed.find(path) != nil) {
+		return svr.allowedMethods(path, method)
+	}
+
+	return nil
+}
+
+// allowedRef returns a reference to the allowed methods for a Route. Returns <nil> without allocating if there
+// aren't any, so that the not found misses don't allocate.
+func allowedRef(allowed []string) *[]string {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	ref := allowed
+	return &ref
+}
+
+// missKind returns the kind of the miss for the request which didn't match a route. allowed is the result of missAllowed.
+func (svr *Server) missKind(method string, allowed []string) MissKind {
+	switch {
+	case len(allowed) == 0:
+		return MissNotFound
+	case method == http.MethodOptions && svr.config.autoOptionsHandler != nil:
+		return MissAutoOptions
+	default:
+		return MissMethodNotAllowed
+	}
+}
+
+// serveNotAcceptable replies to the request which didn't satisfy the matchers of any route registered for the path
+// with HTTP 406 (http.StatusNotAcceptable) status, executing the global middlewares if any.
+func (svr *Server) serveNotAcceptable(w http.ResponseWriter, r *http.Request, hostPs *internalParams) {
+	route := Route{
+		Params: Params{nil, hostPs, false},
+		Miss:   MissNotAcceptable,
+		svr:    svr,
+	}
+
+	if svr.miss != nil {
+		_ = svr.miss(w, r, route)
+		return
+	}
+
+	if err := svr.handleGlobalMiss(w, r, route); err != nil {
+		svr.config.errorHandler(w, r, route, err)
 	}
+}
+
+// handleGlobalMiss replies to the request which didn't match a route. It's the last handler of the global middlewares
+// chain for misses. MissNotAcceptable is handed over to the router's error handler as an HTTPError.
+func (svr *Server) handleGlobalMiss(w http.ResponseWriter, r *http.Request, route Route) error {
+	if route.Miss == MissNotAcceptable {
+		return replyError(w, r, route, NewHTTPError(http.StatusNotAcceptable, ""))
+	}
+
+	svr.handleMiss(w, r, svr.requestPath(r), route.Params.host, route.allowedMethods())
+	return nil
+}
+
+// redirectRequest redirects the request to its URL, executing the global middlewares if any.
+func (svr *Server) redirectRequest(w http.ResponseWriter, r *http.Request, hostPs *internalParams, kind MissKind) {
+	route := Route{
+		Params: Params{nil, hostPs, false},
+		Miss:   kind,
+		svr:    svr,
+	}
+
+	if svr.redirect != nil {
+		_ = svr.redirect(w, r, route)
+		return
+	}
+
+	_ = svr.handleRedirect(w, r, route)
+}
 
-	if mux == nil {
-		if svr.config.handleMethodNotAllowed {
-			svr.handleMethodNotAllowed(path, r.Method, w)
+// handleRedirect redirects the request to its URL with the status code configured for the kind of the redirect.
+func (svr *Server) handleRedirect(w http.ResponseWriter, r *http.Request, route Route) error {
+	code := svr.config.trailingSlashMatch.code
+	if route.Miss == MissPathCorrectionRedirect {
+		code = svr.config.pathCorrectionMatch.code
+	}
+
+	http.Redirect(w, r, r.URL.String(), code)
+	return nil
+}
+
+// handleMiss replies to the request which didn't match a route. allowed is the result of missAllowed.
+func (svr *Server) handleMiss(w http.ResponseWriter, r *http.Request, path string, hostPs *internalParams, allowed []string) {
+	if len(allowed) == 0 {
+		svr.handleNotFound(w, r, path, hostPs)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	// Reply to OPTIONS requests with the allowed methods.
+	if r.Method == http.MethodOptions && svr.config.autoOptionsHandler != nil {
+		svr.config.autoOptionsHandler(w, r)
+		return
+	}
+
+	if svr.misses != nil {
+		if methodNotAllowed := svr.misses.methodNotAllowed.find(path); methodNotAllowed != nil {
+			_ = methodNotAllowed(w, r, Route{Params: Params{nil, hostPs, false}, svr: svr, allowed: allowedRef(allowed)})
+			return
 		}
+	}
 
-		svr.config.notFoundHandler(w, r)
+	svr.config.methodNotAllowedHandler(w, r, allowed)
+}
+
+// handleNotFound replies to the request with the not found handler of the deepest Group covering the path, or the
+// Router's not found handler.
+func (svr *Server) handleNotFound(w http.ResponseWriter, r *http.Request, path string, hostPs *internalParams) {
+	var notFound HandlerFunc
+	if svr.misses != nil {
+		notFound = svr.misses.notFound.find(path)
+	}
+
+	if notFound != nil {
+		_ = notFound(w, r, Route{Params: Params{nil, hostPs, false}, svr: svr})
 		return
 	}
 
-	handler, ps, template := mux.find(path)
+	svr.config.notFoundHandler(w, r)
+}
+
+// dispatch executes the route of the mux matching the path, following the trailing slash match and the path
+// correction match when enabled. Returns false if a route was not found.
+func (svr *Server) dispatch(w http.ResponseWriter, r *http.Request, mux multiplexer, path string, hostPs *internalParams) bool {
+	handler, ps, template, meta := mux.find(path)
 	if handler != nil {
 		_ = handler(w, r, Route{
-			Params: newParams(ps), // ps could be <nil> as well, but that's okay!
+			Params: Params{ps, hostPs, svr.config.decodeParams()}, // ps and hostPs could be <nil> as well, but that's okay!
 			Path:   template,
+			Meta:   meta,
+			svr:    svr,
 		})
-		return
+		return true
 	}
 
 	// Look with/without trailing slash.
@@ -53,20 +246,26 @@ func (svr *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			clean = path + "/"
 		}
 
-		handler, ps, template = mux.find(clean)
+		handler, ps, template, meta = mux.find(clean)
 		if handler != nil {
 			switch svr.config.trailingSlashMatch.behavior {
 			case behaviorRedirect:
+				if ps != nil {
+					mux.release(ps)
+				}
+
 				r.URL.Path = clean
-				http.Redirect(w, r, r.URL.String(), svr.config.trailingSlashMatch.code)
-				return
+				svr.redirectRequest(w, r, hostPs, MissTrailingSlashRedirect)
+				return true
 			case behaviorExecute:
 				r.URL.Path = clean
 				_ = handler(w, r, Route{
-					Params: newParams(ps), // ps could be <nil> here too, but that's okay!
+					Params: Params{ps, hostPs, svr.config.decodeParams()}, // ps and hostPs could be <nil> here too, but that's okay!
 					Path:   template,
+					Meta:   meta,
+					svr:    svr,
 				})
-				return
+				return true
 			}
 		}
 	}
@@ -74,52 +273,196 @@ func (svr *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Correct the path and do a case-insensitive search...
 	if svr.config.pathCorrectionMatch.behavior != behaviorSkip {
 		clean := cleanPath(path)
-		handler, ps, template, matchedPath := mux.findCaseInsensitive(clean, svr.config.pathCorrectionMatch.behavior == behaviorExecute)
+		handler, ps, template, meta, matchedPath := mux.findCaseInsensitive(clean, svr.config.pathCorrectionMatch.behavior == behaviorExecute)
 		if handler != nil {
 			switch svr.config.pathCorrectionMatch.behavior {
 			case behaviorRedirect:
 				r.URL.Path = matchedPath
-				http.Redirect(w, r, r.URL.String(), svr.config.pathCorrectionMatch.code)
-				return
+				svr.redirectRequest(w, r, hostPs, MissPathCorrectionRedirect)
+				return true
 			case behaviorExecute:
 				_ = handler(w, r, Route{
-					Params: newParams(ps), // ps could be <nil> here too, but that's okay!
+					Params: Params{ps, hostPs, svr.config.decodeParams()}, // ps and hostPs could be <nil> here too, but that's okay!
 					Path:   template,
+					Meta:   meta,
+					svr:    svr,
 				})
-				return
+				return true
 			}
 		}
 	}
 
-	// Look for allowed methods.
-	if svr.config.handleMethodNotAllowed {
-		svr.handleMethodNotAllowed(path, r.Method, w)
+	return false
+}
+
+// Routes returns the routes the Server was generated with.
+func (svr *Server) Routes() []RouteInfo {
+	routes := make([]RouteInfo, len(svr.routes))
+	copy(routes, svr.routes)
+	return routes
+}
+
+func (svr *Server) mux(method string) multiplexer {
+	if idx := methodIndex(method); idx >= 0 {
+		return svr.muxes[idx]
 	}
+	return svr.customMuxes[method]
+}
 
-	svr.config.notFoundHandler(w, r)
-	return
+// MatchKind describes how a route was matched.
+type MatchKind uint8
+
+const (
+	// MatchExact denotes the route matched the requested path as is.
+	MatchExact MatchKind = iota + 1
+
+	// MatchTrailingSlash denotes the route matched the requested path with/without the trailing slash.
+	MatchTrailingSlash
+
+	// MatchPathCorrection denotes the route matched the corrected requested path using a case-insensitive search.
+	MatchPathCorrection
+)
+
+// String returns the name of the MatchKind.
+func (k MatchKind) String() string {
+	switch k {
+	case MatchExact:
+		return "exact"
+	case MatchTrailingSlash:
+		return "trailing slash"
+	case MatchPathCorrection:
+		return "path correction"
+	default:
+		return ""
+	}
 }
 
-func (svr *Server) populateRoutes(byMethods map[string]*methodInfo) {
-	for method, info := range byMethods {
-		var mux multiplexer
+// RouteMatch describes the route which would handle a request.
+type RouteMatch struct {
+	Method      string
+	Path        string    // Path template of the matched route.
+	Params      Params    // A copy of the route params. It's safe to use beyond the lookup.
+	Kind        MatchKind // Describes whether a fallback (trailing slash match or path correction match) fired.
+	MatchedPath string    // Requested path for exact matches. Otherwise, the path the router would redirect to or execute with.
+	Meta        *RouteMeta
+}
+
+// Lookup finds the route which would handle a request with the provided method and path, without executing the request handler.
+// It follows the same routing rules as ServeHTTP, including the trailing slash match and the path correction match when enabled.
+//
+// Returns false as the second return value if a route was not found.
+//
+// When Router.UseAutoHead is enabled, HEAD lookups fallback to the GET routes. Method of the returned RouteMatch is GET in that case.
+// Routes registered for host patterns are not looked up. Use LookupHost to look them up.
+func (svr *Server) Lookup(method string, path string) (RouteMatch, bool) {
+	if mux := svr.mux(method); mux != nil {
+		if match, ok := svr.lookup(mux, method, path); ok {
+			return match, true
+		}
+	}
 
-		total := len(info.logs) // Total routes in the method.
-		staticPercentage := float64(info.staticRoutes) / float64(total) * 100
+	if method == http.MethodHead && svr.config.autoHead && svr.muxes[0] != nil {
+		return svr.lookup(svr.muxes[0], http.MethodGet, path)
+	}
+
+	return RouteMatch{}, false
+}
+
+// LookupHost finds the route which would handle a request with the provided host, method and path, without executing
+// the request handler. The routes registered for the host pattern matching the host are looked up the same way as
+// ServeHTTP selects them, falling back to the routes registered without a host. Params of the returned RouteMatch
+// include the params captured from the host pattern.
+//
+//	match, ok := srv.LookupHost("acme.example.com", http.MethodGet, "/users/42")
+//	match.Params.Get("tenant") // acme
+//
+// Lookup only looks up the routes registered without a host.
+func (svr *Server) LookupHost(host string, method string, path string) (RouteMatch, bool) {
+	if svr.config.stripHostPort {
+		host = stripPort(host)
+	}
+
+	for _, h := range svr.hosts {
+		if !h.match(host) {
+			continue
+		}
+
+		match, ok := h.svr.Lookup(method, path)
+		if ok {
+			if hostPs := h.params(host); hostPs != nil {
+				match.Params.host = hostPs.deepCopy()
+				h.release(hostPs)
+			}
+		}
+		return match, ok
+	}
 
-		// Determine mux variant.
-		if staticPercentage == 100 {
-			mux = newStaticMux()
-		} else if staticPercentage >= 30 {
-			mux = newHybridMux()
+	return svr.Lookup(method, path)
+}
+
+func (svr *Server) lookup(mux multiplexer, method string, path string) (RouteMatch, bool) {
+	if handler, ps, template, meta := mux.find(path); handler != nil {
+		return RouteMatch{
+			Method:      method,
+			Path:        template,
+			Params:      copyParams(mux, ps, svr.config.decodeParams()),
+			Meta:        meta,
+			Kind:        MatchExact,
+			MatchedPath: path,
+		}, true
+	}
+
+	if svr.config.trailingSlashMatch.behavior != behaviorSkip {
+		var clean string
+		if len(path) > 0 && path[len(path)-1] == '/' {
+			clean = path[:len(path)-1]
 		} else {
-			mux = newRadixMux()
+			clean = path + "/"
 		}
 
-		// Register routes.
-		for _, log := range info.logs {
-			mux.add(log.path, log.static, log.handler)
+		if handler, ps, template, meta := mux.find(clean); handler != nil {
+			return RouteMatch{
+				Method:      method,
+				Path:        template,
+				Params:      copyParams(mux, ps, svr.config.decodeParams()),
+				Meta:        meta,
+				Kind:        MatchTrailingSlash,
+				MatchedPath: clean,
+			}, true
 		}
+	}
+
+	if svr.config.pathCorrectionMatch.behavior != behaviorSkip {
+		if handler, ps, template, meta, matchedPath := mux.findCaseInsensitive(cleanPath(path), true); handler != nil {
+			return RouteMatch{
+				Method:      method,
+				Path:        template,
+				Params:      copyParams(mux, ps, svr.config.decodeParams()),
+				Meta:        meta,
+				Kind:        MatchPathCorrection,
+				MatchedPath: matchedPath,
+			}, true
+		}
+	}
+
+	return RouteMatch{}, false
+}
+
+// copyParams returns a copy of the internalParams object and releases the internalParams object back to the mux.
+func copyParams(mux multiplexer, ps *internalParams, decode bool) Params {
+	if ps == nil {
+		return Params{}
+	}
+
+	cp := Params{internal: ps.deepCopy(), decode: decode}
+	mux.release(ps)
+	return cp
+}
+
+func (svr *Server) populateRoutes(byMethods map[string]*methodInfo) {
+	for method, info := range byMethods {
+		mux := svr.newMux(info)
+		checkStaticVariants(info.logs)
 
 		// Store mux.
 		if idx := methodIndex(method); idx >= 0 {
@@ -140,51 +483,158 @@ func (svr *Server) populateRoutes(byMethods map[string]*methodInfo) {
 
 }
 
-func (svr *Server) handleMethodNotAllowed(path string, method string, w http.ResponseWriter) {
-	allowed := svr.allowedHeader(path, method)
+// checkStaticVariants panics if a static path expanded from a route with optional segments is registered as a static
+// route as well. The radix tree detects such conflicts on insertion, but the hybrid mux stores the static routes apart
+// from the tree. Thus, they're checked for all the mux variants alike. Duplicate static routes are left to the muxes.
+func checkStaticVariants(logs []routeInfo) {
+	static := map[string]string{} // Static path -> template of the route registering it.
+	for _, log := range logs {
+		for _, path := range expandOptionalSegments(log.path) {
+			if !isStatic(path) {
+				continue
+			}
+
+			template, ok := static[path]
+			if !ok {
+				static[path] = log.path
+				continue
+			}
+
+			if template != path || log.path != path {
+				panic(fmt.Sprintf("%s conflicts with already registered route %s", log.path, template))
+			}
+		}
+	}
+}
+
+// newMux returns a multiplexer populated with the routes of the method.
+func (svr *Server) newMux(info *methodInfo) multiplexer {
+	var mux multiplexer
+
+	total := len(info.logs) // Total routes in the method.
+	staticPercentage := float64(info.staticRoutes) / float64(total) * 100
+
+	// Determine mux variant.
+	if staticPercentage == 100 {
+		mux = newStaticMux()
+	} else if staticPercentage >= 30 {
+		mux = newHybridMux(svr.config.constraints)
+	} else {
+		mux = newRadixMux(svr.config.constraints)
+	}
+
+	// Register routes.
+	for _, log := range info.logs {
+		var handler HandlerFunc
+		if len(log.candidates) > 0 {
+			handlers := make([]HandlerFunc, len(log.candidates))
+			for i, c := range log.candidates {
+				handlers[i] = svr.globalHandler(c.handler)
+			}
+			handler = conditionalHandler(log.candidates, handlers)
+		} else {
+			handler = svr.globalHandler(log.handler)
+		}
+
+		mux.add(log.path, log.static, handler, log.meta)
+	}
+
+	return mux
+}
+
+// globalHandler chains the handler of a route with the global middlewares and the error handler.
+func (svr *Server) globalHandler(handler HandlerFunc) HandlerFunc {
+	handler = svr.chainGlobal(handler)
+	if svr.config.errorHandler != nil {
+		handler = errorHandlerWrapper(svr.config, handler)
+	}
+	return handler
+}
+
+// populateGlobalHandlers chains the handlers replying to the requests which didn't match a route with the global middlewares.
+func (svr *Server) populateGlobalHandlers() {
+	if len(svr.config.globalMiddlewares) == 0 {
+		return
+	}
+
+	svr.miss = svr.chainGlobal(svr.handleGlobalMiss)
+	svr.redirect = svr.chainGlobal(svr.handleRedirect)
+
+	if svr.config.errorHandler != nil {
+		svr.miss = errorHandlerWrapper(svr.config, svr.miss)
+		svr.redirect = errorHandlerWrapper(svr.config, svr.redirect)
+	}
+}
 
-	if len(allowed) > 0 {
-		w.Header().Add("Allow", allowed)
-		w.WriteHeader(http.StatusMethodNotAllowed)
+// chainGlobal chains the handler with the global middlewares.
+func (svr *Server) chainGlobal(handler HandlerFunc) HandlerFunc {
+	for i := len(svr.config.globalMiddlewares) - 1; i >= 0; i-- {
+		handler = svr.config.globalMiddlewares[i](handler)
 	}
+	return handler
 }
 
+// allowedHeader returns the value of the 'Allow' header for the path. See allowedMethods.
 func (svr *Server) allowedHeader(path string, skipMethod string) string {
-	var allowed strings.Builder
-	skipped := false
+	return strings.Join(svr.allowedMethods(path, skipMethod), ", ")
+}
 
-	if skipMethodIdx := methodIndex(skipMethod); skipMethodIdx != -1 {
-		for _, idx := range svr.muxIndices {
-			if !skipped && idx == skipMethodIdx {
-				skipped = true
-				continue
+// allowedMethods returns the HTTP methods registered for the path excluding the skipMethod.
+// Built-in HTTP methods are placed first, followed by the custom HTTP methods in the lexical order.
+func (svr *Server) allowedMethods(path string, skipMethod string) (allowed []string) {
+	allowGet, allowHead, allowOptions := false, false, false
+
+	skipMethodIdx := methodIndex(skipMethod)
+	for _, idx := range svr.muxIndices {
+		if idx == skipMethodIdx {
+			continue
+		}
+
+		if handler, ps, _, _ := svr.muxes[idx].find(path); handler != nil {
+			if ps != nil {
+				svr.muxes[idx].release(ps)
 			}
 
-			if handler, _, _ := svr.muxes[idx].find(path); handler != nil {
-				if allowed.Len() != 0 {
-					allowed.WriteString(", ")
-				}
+			allowed = append(allowed, methodString(idx))
 
-				allowed.WriteString(methodString(idx))
+			switch idx {
+			case 0:
+				allowGet = true
+			case 5:
+				allowHead = true
+			case 6:
+				allowOptions = true
 			}
 		}
 	}
 
+	// GET routes serve HEAD requests when the automatic HEAD handling is enabled.
+	if svr.config.autoHead && allowGet && !allowHead && skipMethod != http.MethodHead {
+		allowed = append(allowed, http.MethodHead)
+	}
+
+	builtIn := len(allowed)
 	for method, mux := range svr.customMuxes {
-		if !skipped && method == skipMethod {
+		if method == skipMethod {
 			continue
 		}
 
-		if handler, _, _ := mux.find(path); handler != nil {
-			if allowed.Len() != 0 {
-				allowed.WriteString(", ")
+		if handler, ps, _, _ := mux.find(path); handler != nil {
+			if ps != nil {
+				mux.release(ps)
 			}
 
-			allowed.WriteString(method)
+			allowed = append(allowed, method)
 		}
 	}
+	sort.Strings(allowed[builtIn:])
 
-	return allowed.String()
+	// Every registered path answers OPTIONS requests when the automatic OPTIONS handling is enabled.
+	if svr.config.autoOptionsHandler != nil && len(allowed) != 0 && !allowOptions {
+		allowed = append(allowed, http.MethodOptions)
+	}
+
+	return
 }
 
 func methodIndex(method string) int {
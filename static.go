@@ -0,0 +1,361 @@
+package shift
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staticParam is the name of the wildcard param matching the file paths under the prefix of Core.Static.
+const staticParam = "filepath"
+
+// StaticOptions configures the file serving of Core.Static.
+type StaticOptions struct {
+	// Index is the file served for the directories. Defaults to index.html.
+	Index string
+
+	// Browse enables the directory listings for the directories without an index file.
+	Browse bool
+
+	// MaxAge sets the max-age directive of the Cache-Control header. The header is not set if MaxAge is zero.
+	MaxAge time.Duration
+}
+
+// precompressedEncodings are the content codings of the precompressed siblings, in the order of preference.
+var precompressedEncodings = []struct {
+	coding string
+	ext    string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Static serves the files of fsys under the prefix. e.g.: embed.FS, os.DirFS
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	sub, _ := fs.Sub(assets, "assets")
+//	router.Static("/assets", sub, shift.StaticOptions{MaxAge: 24 * time.Hour})
+//
+// It registers the GET and HEAD routes at prefix/*filepath. Requested paths are cleaned before opening the files,
+// so that the requests cannot traverse outside the root of fsys.
+//
+// When a file has a sibling with the .br or .gz extension and the request accepts the encoding in the Accept-Encoding
+// header, the sibling is served with the Content-Encoding header instead. Responses carry strong ETags generated from
+// the content hashes, and conditional requests and range requests are handled using http.ServeContent.
+//
+// ETags are cached by the name, the size and the modification time of the files, so that the files are not hashed on
+// every request. Hence, a file of a writable file system (e.g.: os.DirFS) modified without changing its size or its
+// modification time keeps the stale ETag. Files must not change while being served, otherwise.
+//
+// Requests for the directories are redirected to the path with the trailing slash, which serves the index file or the
+// directory listing when enabled. Missing files are handed over to the not found handler.
+func (c *Core) Static(prefix string, fsys fs.FS, opts StaticOptions) {
+	if fsys == nil {
+		panic("file system cannot be nil")
+	}
+
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+
+	s := &staticServer{fsys: fsys, opts: opts}
+	c.Map([]string{http.MethodGet, http.MethodHead}, strings.TrimRight(prefix, "/")+"/*"+staticParam, s.serve)
+}
+
+// SPA serves a single-page application from fsys under the prefix. index is the path of the index document in fsys.
+//
+//	router.SPA("/", sub, "index.html")
+//	router.GET("/api/users", ListUsers) // Routes registered elsewhere keep the priority.
+//
+// Existing files are served the same way as Core.Static. Other GET and HEAD requests under the prefix which accept
+// text/html are replied with the index document, so that the application can route them on the client side.
+// Requests for missing asset-looking paths (e.g.: /app.js, /style.css) and requests not accepting text/html are handed
+// over to the not found handler. Paths are asset-looking when they have the extension of a common web asset, such as
+// scripts, style sheets, images, fonts and media files. The responses to the other missing paths vary by the Accept header.
+//
+// SPA panics if the index document doesn't exist in fsys.
+func (c *Core) SPA(prefix string, fsys fs.FS, index string) {
+	if fsys == nil {
+		panic("file system cannot be nil")
+	}
+
+	if info, err := fs.Stat(fsys, index); err != nil || info.IsDir() {
+		panic(fmt.Sprintf("index document %s not found", index))
+	}
+
+	s := &staticServer{fsys: fsys, opts: StaticOptions{Index: path.Base(index)}, fallback: index}
+	prefix = strings.TrimRight(prefix, "/")
+	if c.base+prefix != "" {
+		// Matches the prefix itself, which is redirected to the prefix with the trailing slash.
+		c.Map([]string{http.MethodGet, http.MethodHead}, prefix, s.serve)
+	}
+	c.Map([]string{http.MethodGet, http.MethodHead}, prefix+"/*"+staticParam, s.serve)
+}
+
+type staticServer struct {
+	fsys     fs.FS
+	opts     StaticOptions
+	fallback string   // Document served for the missing paths accepting text/html. Set for the SPAs only.
+	etags    sync.Map // staticKey -> ETag.
+}
+
+// acceptsHTML matches the requests accepting HTML documents, i.e. the navigations of the browsers.
+var acceptsHTML = MatchAccept("text/html")
+
+// staticKey identifies a version of a file for caching its ETag.
+type staticKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (s *staticServer) serve(w http.ResponseWriter, r *http.Request, route Route) error {
+	// The file path is retrieved as matched, and unescaped when the route was matched against the escaped path.
+	ps := route.Params
+	ps.decode = false
+	rel := ps.Get(staticParam)
+	if route.svr.config.matchesEscapedPath(r) {
+		rel = unescapeParam(rel)
+	}
+
+	name := strings.Trim(cleanPath("/"+rel), "/")
+	if name == "" {
+		name = "."
+	}
+
+	if !fs.ValidPath(name) {
+		return s.notFound(w, r, route)
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return s.fail(w, r, route, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return s.fail(w, r, route, err)
+	}
+
+	if info.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			redirectDir(w, r)
+			return nil
+		}
+
+		index := path.Join(name, s.opts.Index)
+		if idx, err := s.fsys.Open(index); err == nil {
+			defer idx.Close()
+			if info, err := idx.Stat(); err == nil && !info.IsDir() {
+				return s.serveFile(w, r, route, index, idx, info)
+			}
+		}
+
+		if !s.opts.Browse {
+			return s.notFound(w, r, route)
+		}
+		return s.serveDir(w, r, route, name)
+	}
+
+	return s.serveFile(w, r, route, name, f, info)
+}
+
+// serveFile serves the file or its precompressed sibling accepted by the request.
+func (s *staticServer) serveFile(w http.ResponseWriter, r *http.Request, route Route, name string, f fs.File, info fs.FileInfo) error {
+	ctype := mime.TypeByExtension(path.Ext(name))
+
+	// Precompressed siblings are served only when the content type is known, since it cannot be sniffed from the encoded content.
+	if ctype != "" {
+		vary := false
+		for _, enc := range precompressedEncodings {
+			cf, err := s.fsys.Open(name + enc.ext)
+			if err != nil {
+				continue
+			}
+
+			// The response varies by the Accept-Encoding header whenever a sibling exists.
+			if !vary {
+				w.Header().Add("Vary", "Accept-Encoding")
+				vary = true
+			}
+
+			if cinfo, err := cf.Stat(); err == nil && !cinfo.IsDir() && acceptsEncoding(r.Header.Get("Accept-Encoding"), enc.coding) {
+				defer cf.Close()
+				return s.serveContent(w, r, route, name+enc.ext, cf, cinfo, enc.coding, ctype)
+			}
+			_ = cf.Close()
+		}
+	}
+
+	return s.serveContent(w, r, route, name, f, info, "", "")
+}
+
+// serveContent serves the content of the file. The coding and the content type of a precompressed sibling are set
+// only once the content is about to be served, so that the error responses don't carry them.
+func (s *staticServer) serveContent(w http.ResponseWriter, r *http.Request, route Route, name string, f fs.File, info fs.FileInfo, coding string, ctype string) error {
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return s.fail(w, r, route, err)
+		}
+		content = bytes.NewReader(b)
+	}
+
+	etag, err := s.etag(name, content, info)
+	if err != nil {
+		return s.fail(w, r, route, err)
+	}
+
+	if coding != "" {
+		w.Header().Set("Content-Encoding", coding)
+		w.Header().Set("Content-Type", ctype)
+	}
+
+	w.Header().Set("ETag", etag)
+	if s.opts.MaxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(s.opts.MaxAge/time.Second), 10))
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return nil
+}
+
+// etag returns the strong ETag of the content, which is cached for the version of the file.
+// The version is identified by the size and the modification time. See Core.Static.
+func (s *staticServer) etag(name string, content io.ReadSeeker, info fs.FileInfo) (string, error) {
+	key := staticKey{name, info.Size(), info.ModTime()}
+	if etag, ok := s.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	s.etags.Store(key, etag)
+	return etag, nil
+}
+
+// serveDir replies with the HTML listing of the directory.
+func (s *staticServer) serveDir(w http.ResponseWriter, r *http.Request, route Route, name string) error {
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		return s.fail(w, r, route, err)
+	}
+
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		n := entry.Name()
+		if entry.IsDir() {
+			n += "/"
+		}
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", html.EscapeString((&url.URL{Path: n}).String()), html.EscapeString(n))
+	}
+	b.WriteString("</pre>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method != http.MethodHead {
+		_, _ = io.WriteString(w, b.String())
+	}
+	return nil
+}
+
+func (s *staticServer) notFound(w http.ResponseWriter, r *http.Request, route Route) error {
+	if s.fallback != "" && !isAssetPath(r.URL.Path) {
+		// Whether the fallback is served depends on the Accept header.
+		w.Header().Add("Vary", "Accept")
+
+		if acceptsHTML(r) {
+			if f, err := s.fsys.Open(s.fallback); err == nil {
+				defer f.Close()
+				if info, err := f.Stat(); err == nil {
+					return s.serveFile(w, r, route, s.fallback, f, info)
+				}
+			}
+		}
+	}
+
+	route.svr.handleNotFound(w, r, route.svr.requestPath(r), route.Params.host)
+	return nil
+}
+
+// assetExts are the extensions of the asset files, which are never replied with the fallback document of the SPAs.
+// The list is fixed rather than looked up with mime.TypeByExtension, whose results depend on the host.
+var assetExts = map[string]bool{
+	".js": true, ".mjs": true, ".cjs": true, ".css": true, ".map": true, ".json": true, ".webmanifest": true,
+	".xml": true, ".txt": true, ".csv": true, ".pdf": true, ".wasm": true, ".zip": true, ".gz": true, ".br": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true, ".svg": true, ".ico": true, ".bmp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp3": true, ".mp4": true, ".m4a": true, ".ogg": true, ".wav": true, ".webm": true,
+}
+
+// isAssetPath reports whether the path has the extension of an asset file. e.g.: /app.js
+func isAssetPath(p string) bool {
+	return assetExts[strings.ToLower(path.Ext(p))]
+}
+
+// fail replies to the request which failed to open or read the file.
+func (s *staticServer) fail(w http.ResponseWriter, r *http.Request, route Route, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return s.notFound(w, r, route)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// redirectDir redirects the request to the path with the trailing slash, preserving the query.
+func redirectDir(w http.ResponseWriter, r *http.Request) {
+	target := path.Base(r.URL.Path) + "/"
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	w.Header().Set("Location", target)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header accepts the content coding with a non-zero quality.
+func acceptsEncoding(header string, coding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(part, ";")
+		token = strings.TrimSpace(token)
+		if !strings.EqualFold(token, coding) && token != "*" {
+			continue
+		}
+
+		params = strings.TrimSpace(params)
+		if strings.HasPrefix(params, "q=") {
+			if f, err := strconv.ParseFloat(params[2:], 64); err == nil && f == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package shift
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newStaticTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":          {Data: []byte("<h1>home</h1>")},
+		"app.js":              {Data: []byte("console.log('app')")},
+		"app.js.br":           {Data: []byte("br:app")},
+		"app.js.gz":           {Data: []byte("gz:app")},
+		"style.css":           {Data: []byte("body{}")},
+		"style.css.gz":        {Data: []byte("gz:style")},
+		"docs/readme.txt":     {Data: []byte("0123456789")},
+		"docs/sub/a b.txt":    {Data: []byte("spaced")},
+		"docs/<script>.txt":   {Data: []byte("escaped")},
+		"empty/index.html":    {Data: []byte("<h1>empty</h1>")},
+		"nested/index.htm":    {Data: []byte("<h1>htm</h1>")},
+		"nested/deep/file.md": {Data: []byte("# deep")},
+	}
+}
+
+func TestCore_Static(t *testing.T) {
+	r := newTestRouter()
+	r.Static("/assets/", newStaticTestFS(), StaticOptions{MaxAge: time.Hour})
+	r.GET("/secret", fakeHandler())
+	srv := r.Serve()
+
+	tt := []struct {
+		path     string
+		encoding string
+		code     int
+		body     string
+		coding   string
+		ctype    string
+		location string
+	}{
+		{path: "/assets/app.js", code: 200, body: "console.log('app')", ctype: "text/javascript; charset=utf-8"},
+		{path: "/assets/app.js", encoding: "gzip, br", code: 200, body: "br:app", coding: "br", ctype: "text/javascript; charset=utf-8"},
+		{path: "/assets/app.js", encoding: "gzip", code: 200, body: "gz:app", coding: "gzip", ctype: "text/javascript; charset=utf-8"},
+		{path: "/assets/app.js", encoding: "br;q=0, gzip", code: 200, body: "gz:app", coding: "gzip"},
+		{path: "/assets/app.js", encoding: "identity", code: 200, body: "console.log('app')"},
+		{path: "/assets/style.css", encoding: "br", code: 200, body: "body{}"},
+		{path: "/assets/style.css", encoding: "*", code: 200, body: "gz:style", coding: "gzip"},
+		{path: "/assets/", code: 200, body: "<h1>home</h1>", ctype: "text/html; charset=utf-8"},
+		{path: "/assets/empty/", code: 200, body: "<h1>empty</h1>"},
+		{path: "/assets/empty", code: 301, location: "empty/"},
+		{path: "/assets/nested/", code: 404},
+		{path: "/assets/docs/sub/a%20b.txt", code: 200, body: "spaced"},
+		{path: "/assets/docs/../app.js", code: 200, body: "console.log('app')"},
+		{path: "/assets/../secret", code: 404},
+		{path: "/assets/%2e%2e/secret", code: 404},
+		{path: "/assets/missing.js", code: 404},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.path+" "+tx.encoding, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tx.path, nil)
+			if tx.encoding != "" {
+				req.Header.Set("Accept-Encoding", tx.encoding)
+			}
+
+			srv.ServeHTTP(rw, req)
+			assert(t, rw.Code == tx.code, fmt.Sprintf("status code > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.code == 301 {
+				assert(t, rw.Header().Get("Location") == tx.location, fmt.Sprintf("location > expected: %s, got: %s", tx.location, rw.Header().Get("Location")))
+			}
+			if tx.code != 200 {
+				return
+			}
+
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			assert(t, rw.Header().Get("Content-Encoding") == tx.coding, fmt.Sprintf("content encoding > expected: %s, got: %s", tx.coding, rw.Header().Get("Content-Encoding")))
+			if tx.ctype != "" {
+				assert(t, rw.Header().Get("Content-Type") == tx.ctype, fmt.Sprintf("content type > expected: %s, got: %s", tx.ctype, rw.Header().Get("Content-Type")))
+			}
+			assert(t, rw.Header().Get("Cache-Control") == "public, max-age=3600", fmt.Sprintf("cache control > expected: public, max-age=3600, got: %s", rw.Header().Get("Cache-Control")))
+			assert(t, strings.HasPrefix(rw.Header().Get("ETag"), `"`), fmt.Sprintf("etag > expected a strong etag, got: %s", rw.Header().Get("ETag")))
+		})
+	}
+}
+
+// failingReadFS fails reading the files having the extension.
+type failingReadFS struct {
+	fstest.MapFS
+	ext string
+}
+
+func (f failingReadFS) Open(name string) (fs.File, error) {
+	file, err := f.MapFS.Open(name)
+	if err != nil || !strings.HasSuffix(name, f.ext) {
+		return file, err
+	}
+	return failingFile{file}, nil
+}
+
+type failingFile struct {
+	fs.File
+}
+
+func (failingFile) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestCore_Static_PrecompressedFailure(t *testing.T) {
+	r := newTestRouter()
+	r.Static("/assets", failingReadFS{newStaticTestFS(), ".br"}, StaticOptions{})
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	r.Serve().ServeHTTP(rw, req)
+
+	assert(t, rw.Code == http.StatusInternalServerError, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusInternalServerError, rw.Code))
+	assert(t, rw.Header().Get("Content-Encoding") == "", fmt.Sprintf("content encoding > expected: <empty>, got: %s", rw.Header().Get("Content-Encoding")))
+	assert(t, rw.Header().Get("Content-Type") == "text/plain; charset=utf-8", fmt.Sprintf("content type > expected: text/plain; charset=utf-8, got: %s", rw.Header().Get("Content-Type")))
+}
+
+func TestCore_Static_Caching(t *testing.T) {
+	r := newTestRouter()
+	r.Static("/assets", newStaticTestFS(), StaticOptions{})
+	srv := r.Serve()
+
+	get := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		srv.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := get("/assets/app.js", nil)
+	etag := rw.Header().Get("ETag")
+	assert(t, rw.Header().Get("Vary") == "Accept-Encoding", fmt.Sprintf("vary > expected: Accept-Encoding, got: %s", rw.Header().Get("Vary")))
+	assert(t, rw.Header().Get("Cache-Control") == "", fmt.Sprintf("cache control > expected: <empty>, got: %s", rw.Header().Get("Cache-Control")))
+
+	t.Run("etag", func(t *testing.T) {
+		again := get("/assets/app.js", nil).Header().Get("ETag")
+		assert(t, etag == again, fmt.Sprintf("etag > expected: %s, got: %s", etag, again))
+
+		br := get("/assets/app.js", map[string]string{"Accept-Encoding": "br"}).Header().Get("ETag")
+		assert(t, etag != br, "etag > expected different etags for the encodings")
+	})
+
+	t.Run("if none match", func(t *testing.T) {
+		rw := get("/assets/app.js", map[string]string{"If-None-Match": etag})
+		assert(t, rw.Code == http.StatusNotModified, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusNotModified, rw.Code))
+
+		rw = get("/assets/app.js", map[string]string{"If-None-Match": `"stale"`})
+		assert(t, rw.Code == http.StatusOK, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusOK, rw.Code))
+	})
+
+	t.Run("range", func(t *testing.T) {
+		rw := get("/assets/docs/readme.txt", map[string]string{"Range": "bytes=2-5"})
+		assert(t, rw.Code == http.StatusPartialContent, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusPartialContent, rw.Code))
+		assert(t, rw.Body.String() == "2345", fmt.Sprintf("body > expected: 2345, got: %s", rw.Body.String()))
+	})
+
+	t.Run("head", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		srv.ServeHTTP(rw, httptest.NewRequest(http.MethodHead, "/assets/docs/readme.txt", nil))
+		assert(t, rw.Code == http.StatusOK, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusOK, rw.Code))
+		assert(t, rw.Body.Len() == 0, fmt.Sprintf("body > expected: <empty>, got: %s", rw.Body.String()))
+		assert(t, rw.Header().Get("Content-Length") == "10", fmt.Sprintf("content length > expected: 10, got: %s", rw.Header().Get("Content-Length")))
+	})
+}
+
+func TestCore_Static_Browse(t *testing.T) {
+	r := newTestRouter()
+	r.Group("/files", func(g *Group) {
+		g.UseNotFoundHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		g.Static("", newStaticTestFS(), StaticOptions{Index: "index.htm", Browse: true})
+	})
+	srv := r.Serve()
+
+	rw := httptest.NewRecorder()
+	srv.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/files/docs/", nil))
+	assert(t, rw.Code == http.StatusOK, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusOK, rw.Code))
+
+	body := rw.Body.String()
+	for _, entry := range []string{`<a href="readme.txt">readme.txt</a>`, `<a href="sub/">sub/</a>`, `<a href="%3Cscript%3E.txt">&lt;script&gt;.txt</a>`} {
+		assert(t, strings.Contains(body, entry), fmt.Sprintf("listing > expected to contain: %s, got: %s", entry, body))
+	}
+
+	rw = httptest.NewRecorder()
+	srv.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/files/nested/", nil))
+	assert(t, rw.Body.String() == "<h1>htm</h1>", fmt.Sprintf("body > expected: <h1>htm</h1>, got: %s", rw.Body.String()))
+
+	rw = httptest.NewRecorder()
+	srv.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/files/missing", nil))
+	assert(t, rw.Code == http.StatusTeapot, fmt.Sprintf("status code > expected: %d, got: %d", http.StatusTeapot, rw.Code))
+}
+
+func TestCore_SPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dist/index.html":     {Data: []byte("<div id=app></div>")},
+		"dist/assets/app.js":  {Data: []byte("mount()")},
+		"dist/assets/app.css": {Data: []byte("#app{}")},
+	}
+
+	r := newTestRouter()
+	r.GET("/app/api/users", func(w http.ResponseWriter, r *http.Request, route Route) error {
+		_, err := w.Write([]byte("users"))
+		return err
+	})
+	sub, _ := fs.Sub(fsys, "dist")
+	r.SPA("/app", sub, "index.html")
+	r.SPA("/admin", fsys, "dist/index.html")
+	srv := r.Serve()
+
+	const html = "text/html,application/xhtml+xml,*/*;q=0.8"
+
+	tt := []struct {
+		method   string
+		path     string
+		accept   string
+		code     int
+		body     string
+		location string
+	}{
+		{method: http.MethodGet, path: "/app/assets/app.js", accept: "*/*", code: 200, body: "mount()"},
+		{method: http.MethodGet, path: "/app/assets/app.css", accept: html, code: 200, body: "#app{}"},
+		{method: http.MethodGet, path: "/app/", accept: html, code: 200, body: "<div id=app></div>"},
+		{method: http.MethodGet, path: "/app", accept: html, code: 301, location: "app/"},
+		{method: http.MethodGet, path: "/app/users/42", accept: html, code: 200, body: "<div id=app></div>"},
+		{method: http.MethodGet, path: "/app/users/john.doe", accept: html, code: 200, body: "<div id=app></div>"},
+		{method: http.MethodGet, path: "/app/settings.html", accept: html, code: 200, body: "<div id=app></div>"},
+		{method: http.MethodHead, path: "/app/users/42", accept: html, code: 200},
+		{method: http.MethodGet, path: "/app/api/users", accept: html, code: 200, body: "users"},
+		{method: http.MethodGet, path: "/app/api/unknown", accept: "application/json", code: 404},
+		{method: http.MethodGet, path: "/app/users/42", accept: "*/*", code: 404},
+		{method: http.MethodGet, path: "/app/assets/missing.js", accept: html, code: 404},
+		{method: http.MethodGet, path: "/app/missing.css", accept: html, code: 404},
+		{method: http.MethodGet, path: "/app/logo.PNG", accept: html, code: 404},
+		{method: http.MethodGet, path: "/app/font.woff2", accept: html, code: 404},
+		{method: http.MethodGet, path: "/app/report.v2", accept: html, code: 200, body: "<div id=app></div>"},
+		{method: http.MethodPost, path: "/app/users/42", accept: html, code: 404},
+		{method: http.MethodGet, path: "/admin/users", accept: html, code: 200, body: "<div id=app></div>"},
+		{method: http.MethodGet, path: "/admin/dist/assets/app.js", accept: html, code: 200, body: "mount()"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method+" "+tx.path+" "+tx.accept, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tx.method, tx.path, nil)
+			req.Header.Set("Accept", tx.accept)
+
+			srv.ServeHTTP(rw, req)
+			assert(t, rw.Code == tx.code, fmt.Sprintf("status code > expected: %d, got: %d", tx.code, rw.Code))
+			if tx.location != "" {
+				assert(t, rw.Header().Get("Location") == tx.location, fmt.Sprintf("location > expected: %s, got: %s", tx.location, rw.Header().Get("Location")))
+			}
+			if tx.code == 200 {
+				assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+			}
+		})
+	}
+
+	t.Run("vary", func(t *testing.T) {
+		for _, tx := range []struct {
+			path   string
+			accept string
+			vary   string
+		}{
+			{path: "/app/users/42", accept: html, vary: "Accept"},
+			{path: "/app/users/42", accept: "application/json", vary: "Accept"},
+			{path: "/app/missing.js", accept: html, vary: ""},
+			{path: "/app/assets/app.js", accept: html, vary: ""},
+		} {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tx.path, nil)
+			req.Header.Set("Accept", tx.accept)
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Header().Get("Vary") == tx.vary, fmt.Sprintf("%s %s > vary > expected: %s, got: %s", tx.path, tx.accept, tx.vary, rw.Header().Get("Vary")))
+		}
+	})
+
+	t.Run("missing index", func(t *testing.T) {
+		pnk := panicHandler(func() {
+			newTestRouter().SPA("/", fsys, "index.html")
+		})
+		assert(t, pnk != nil, "expected a panic")
+	})
+}
@@ -0,0 +1,464 @@
+package shift
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by the input types of Typed handlers which validate themselves after binding.
+// The error returned by Validate is replied with HTTP 400 (http.StatusBadRequest) status.
+type Validator interface {
+	Validate() error
+}
+
+// BindError describes a failure to bind a request value into the input of a Typed handler.
+type BindError struct {
+	Source string // Source of the value. One of path, query, header, form or body.
+	Field  string // Name of the param, query param, header or form field. Empty for the body.
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid %s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("invalid %s %s: %v", e.Source, e.Field, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCoder is implemented by the outputs of Typed handlers which are replied with a status other than
+// HTTP 200 (http.StatusOK). e.g.: HTTP 201 (http.StatusCreated)
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// ErrMissingValue is returned within a BindError when a required value is not present in the request.
+var ErrMissingValue = errors.New("missing value")
+
+// Typed adapts a function taking a typed input and returning a typed output into a HandlerFunc.
+//
+// In must be a struct type. Its fields are bound from the request using struct tags:
+//
+//	type GetOrderInput struct {
+//		ID      int      `path:"id"`
+//		Page    int      `query:"page"`
+//		Fields  []string `query:"field"`
+//		TraceID string   `header:"X-Trace-Id,required"`
+//		Note    string   `form:"note"`         // application/x-www-form-urlencoded and multipart/form-data bodies.
+//		Items   []Item   `json:"items"`        // application/json bodies.
+//	}
+//
+// The JSON body is decoded into In before binding the tagged fields. The request body is ignored when In has neither
+// form fields nor untagged fields to decode the JSON body into. Append ",required" to a tag to reply with an
+// error when the value is not present in the request. When In implements Validator, it's validated after binding.
+//
+// Out is encoded into the response according to the Accept header of the request. JSON (the default) and XML are
+// supported. The Accept header is negotiated before binding, so fn isn't executed for unacceptable requests.
+// The response is replied with HTTP 200 (http.StatusOK) status, unless Out implements StatusCoder. The body is omitted
+// for the statuses which don't permit a body, such as HTTP 204 (http.StatusNoContent).
+//
+// Binding failures (see BindError) and validation failures are returned as HTTPError with HTTP 400 (http.StatusBadRequest)
+// status, unacceptable media types with HTTP 406 (http.StatusNotAcceptable) status and unsupported request bodies with
+// HTTP 415 (http.StatusUnsupportedMediaType) status. These errors are handed over to the router's error handler, or
+// replied using DefaultErrorHandler when an error handler isn't configured. Errors returned by fn are handled as the
+// errors of any other HandlerFunc.
+//
+// JSON bodies larger than 10 MB are rejected with HTTP 413 (http.StatusRequestEntityTooLarge) status.
+//
+// The context passed to fn carries the Route. Use FromContext to retrieve it.
+//
+// Typed panics if In is not a struct type or has tagged fields of unsupported types.
+func Typed[In, Out any](fn func(ctx context.Context, in In) (Out, error)) HandlerFunc {
+	if fn == nil {
+		panic("handler cannot be nil")
+	}
+
+	b := newBinder(reflect.TypeOf((*In)(nil)).Elem())
+
+	return func(w http.ResponseWriter, r *http.Request, route Route) error {
+		mediaType := negotiate(r.Header.Get("Accept"), offers)
+		if mediaType == "" {
+			return replyError(w, r, route, NewHTTPError(http.StatusNotAcceptable, ""))
+		}
+
+		var in In
+		if err := b.bind(reflect.ValueOf(&in).Elem(), w, r, route); err != nil {
+			return replyError(w, r, route, err)
+		}
+
+		if v, ok := any(&in).(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return replyError(w, r, route, badRequest(err))
+			}
+		}
+
+		out, err := fn(WithRoute(r.Context(), route), in)
+		if err != nil {
+			return err
+		}
+
+		code := statusCode(out)
+		if !bodyAllowed(code) {
+			w.WriteHeader(code)
+			return nil
+		}
+
+		body, err := encode(mediaType, out)
+		if err != nil {
+			return replyError(w, r, route, err)
+		}
+
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		w.WriteHeader(code)
+		_, err = w.Write(body)
+		return err
+	}
+}
+
+// statusCode returns the status the output is replied with.
+func statusCode(out any) int {
+	if v := reflect.ValueOf(out); v.Kind() == reflect.Pointer && v.IsNil() {
+		return http.StatusOK
+	}
+
+	if sc, ok := out.(StatusCoder); ok {
+		return sc.StatusCode()
+	}
+	return http.StatusOK
+}
+
+func badRequest(err error) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return err
+	}
+	return NewHTTPError(http.StatusBadRequest, err.Error()).Wrap(err)
+}
+
+// bindSources are the struct tags the fields are bound from, in the binding order.
+var bindSources = []string{"path", "query", "header", "form"}
+
+// binder binds requests into the values of a struct type.
+// The fields are resolved once at creation, so binding doesn't walk the struct tags on every request.
+type binder struct {
+	fields []bindField
+	form   bool // Denotes whether the struct has form fields.
+	body   bool // Denotes whether the struct has form fields or untagged fields the JSON body is decoded into.
+}
+
+type bindField struct {
+	index    []int
+	source   string
+	name     string
+	required bool
+}
+
+func newBinder(t reflect.Type) *binder {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("input type %s must be a struct", t))
+	}
+
+	b := &binder{}
+	for _, field := range reflect.VisibleFields(t) {
+		tagged := false
+		for _, source := range bindSources {
+			tag, ok := field.Tag.Lookup(source)
+			if !ok || tag == "-" {
+				continue
+			}
+
+			if !field.IsExported() {
+				panic(fmt.Sprintf("field %s of %s must be exported", field.Name, t))
+			}
+
+			if !bindable(field.Type) {
+				panic(fmt.Sprintf("field %s of %s has unsupported type %s", field.Name, t, field.Type))
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+
+			b.fields = append(b.fields, bindField{
+				index:    field.Index,
+				source:   source,
+				name:     name,
+				required: hasOption(opts, "required"),
+			})
+			b.form = b.form || source == "form"
+			tagged = true
+		}
+
+		if !tagged && field.IsExported() && !field.Anonymous && field.Tag.Get("json") != "-" {
+			b.body = true
+		}
+	}
+	b.body = b.body || b.form
+
+	return b
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindable reports whether values of the type can be parsed from strings.
+func bindable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer:
+		return bindable(t.Elem())
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && t.Elem().Kind() != reflect.Pointer && bindable(t.Elem())
+	default:
+		return false
+	}
+}
+
+func (b *binder) bind(v reflect.Value, w http.ResponseWriter, r *http.Request, route Route) error {
+	if err := b.bindBody(v, w, r); err != nil {
+		return err
+	}
+
+	var query map[string][]string
+	for _, f := range b.fields {
+		var values []string
+		switch f.source {
+		case "path":
+			if value, ok := route.Params.Lookup(f.name); ok {
+				values = []string{value}
+			}
+		case "query":
+			if query == nil {
+				query = r.URL.Query()
+			}
+			values = query[f.name]
+		case "header":
+			values = r.Header.Values(f.name)
+		case "form":
+			values = r.PostForm[f.name]
+		}
+
+		if len(values) == 0 {
+			if f.required {
+				return badRequest(&BindError{f.source, f.name, ErrMissingValue})
+			}
+			continue
+		}
+
+		if err := setValue(v.FieldByIndex(f.index), values); err != nil {
+			return badRequest(&BindError{f.source, f.name, err})
+		}
+	}
+
+	return nil
+}
+
+// maxJSONBodySize is the maximum size of the JSON bodies decoded by the Typed handlers.
+const maxJSONBodySize = 10 << 20
+
+// bindBody decodes the JSON body into v and parses the form body when v has form fields.
+// JSON bodies exceeding maxJSONBodySize are rejected with HTTP 413 (http.StatusRequestEntityTooLarge) status.
+// The body is ignored when v doesn't bind a body.
+func (b *binder) bindBody(v reflect.Value, w http.ResponseWriter, r *http.Request) error {
+	if !b.body || r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		body := &countingReader{r: http.MaxBytesReader(w, r.Body, maxJSONBodySize)}
+		if err := json.NewDecoder(body).Decode(v.Addr().Interface()); err != nil && err != io.EOF {
+			if body.n >= maxJSONBodySize {
+				return NewHTTPError(http.StatusRequestEntityTooLarge, "").Wrap(err)
+			}
+			return badRequest(&BindError{Source: "body", Err: err})
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return badRequest(&BindError{Source: "body", Err: err})
+		}
+	case mediaType == "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return badRequest(&BindError{Source: "body", Err: err})
+		}
+	default:
+		if b.form || mediaType != "" {
+			return NewHTTPError(http.StatusUnsupportedMediaType, "")
+		}
+	}
+
+	return nil
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// setValue parses the values into v. Slices receive all the values, other types receive the first value.
+func setValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setString(s.Index(i), value); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	return setString(v, values[0])
+}
+
+func setString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setString(v.Elem(), s)
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	}
+
+	return nil
+}
+
+// offers are the media types the typed outputs can be encoded into, in the order of preference.
+var offers = []string{"application/json", "application/xml", "text/xml"}
+
+// encode encodes the value into the media type, which is one of the offers.
+func encode(mediaType string, v any) (body []byte, err error) {
+	if mediaType == "application/json" {
+		body, err = json.Marshal(v)
+	} else {
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		err = xml.NewEncoder(&buf).Encode(v)
+		body = buf.Bytes()
+	}
+	return
+}
+
+// negotiate returns the offer with the highest quality in the Accept header.
+// Offers are preferred in their order when qualities are equal. Returns the first offer if the header is empty,
+// and an empty string if none of the offers are acceptable.
+func negotiate(accept string, offers []string) string {
+	if accept == "" {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality of the most specific media range in the Accept header matching the media type.
+func acceptQuality(accept string, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		switch {
+		case mediaRange == mediaType:
+			s = 2
+		case mediaRange == "*/*":
+			s = 0
+		case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1]):
+			s = 1
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
@@ -0,0 +1,387 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type typedTestInput struct {
+	ID      int           `path:"id"`
+	Page    *int          `query:"page"`
+	Fields  []string      `query:"field"`
+	Since   time.Time     `query:"since"`
+	Timeout time.Duration `query:"timeout"`
+	TraceID string        `header:"X-Trace-Id,required"`
+	Note    string        `form:"note"`
+	Name    string        `json:"name"`
+}
+
+func (in typedTestInput) Validate() error {
+	if in.ID <= 0 {
+		return errors.New("id must be positive")
+	}
+	return nil
+}
+
+type typedTestOutput struct {
+	Summary string `json:"summary" xml:"summary"`
+}
+
+func TestTyped(t *testing.T) {
+	r := New()
+	r.UseErrorHandler(DefaultErrorHandler)
+	r.Map([]string{http.MethodGet, http.MethodPost}, "/orders/:id", Typed(func(ctx context.Context, in typedTestInput) (typedTestOutput, error) {
+		route, _ := FromContext(ctx)
+
+		page := 0
+		if in.Page != nil {
+			page = *in.Page
+		}
+
+		return typedTestOutput{
+			Summary: fmt.Sprintf("%s id=%d page=%d fields=%v since=%s timeout=%s trace=%s note=%s name=%s",
+				route.Path, in.ID, page, in.Fields, in.Since.Format("2006-01-02"), in.Timeout, in.TraceID, in.Note, in.Name),
+		}, nil
+	}))
+	r.GET("/fail", Typed(func(ctx context.Context, in struct{}) (typedTestOutput, error) {
+		return typedTestOutput{}, NewHTTPError(http.StatusConflict, "conflict")
+	}))
+
+	srv := r.Serve()
+
+	tt := []struct {
+		name        string
+		method      string
+		path        string
+		header      map[string]string
+		body        string
+		code        int
+		contentType string
+		response    string
+	}{
+		{
+			name:        "query and header",
+			method:      http.MethodGet,
+			path:        "/orders/42?page=3&field=a&field=b&since=2024-01-02T00:00:00Z&timeout=1m",
+			header:      map[string]string{"X-Trace-Id": "abc"},
+			code:        http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			response:    `{"summary":"/orders/:id id=42 page=3 fields=[a b] since=2024-01-02 timeout=1m0s trace=abc note= name="}`,
+		},
+		{
+			name:        "json body",
+			method:      http.MethodPost,
+			path:        "/orders/42",
+			header:      map[string]string{"X-Trace-Id": "abc", "Content-Type": "application/json"},
+			body:        `{"name":"bob"}`,
+			code:        http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			response:    `{"summary":"/orders/:id id=42 page=0 fields=[] since=0001-01-01 timeout=0s trace=abc note= name=bob"}`,
+		},
+		{
+			name:        "form body",
+			method:      http.MethodPost,
+			path:        "/orders/42",
+			header:      map[string]string{"X-Trace-Id": "abc", "Content-Type": "application/x-www-form-urlencoded"},
+			body:        "note=hello",
+			code:        http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			response:    `{"summary":"/orders/:id id=42 page=0 fields=[] since=0001-01-01 timeout=0s trace=abc note=hello name="}`,
+		},
+		{
+			name:        "xml",
+			method:      http.MethodGet,
+			path:        "/orders/42",
+			header:      map[string]string{"X-Trace-Id": "abc", "Accept": "application/json;q=0.5, application/xml"},
+			code:        http.StatusOK,
+			contentType: "application/xml; charset=utf-8",
+			response:    `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<typedTestOutput><summary>/orders/:id id=42 page=0 fields=[] since=0001-01-01 timeout=0s trace=abc note= name=</summary></typedTestOutput>`,
+		},
+		{
+			name:   "not acceptable",
+			method: http.MethodGet,
+			path:   "/orders/42",
+			header: map[string]string{"X-Trace-Id": "abc", "Accept": "text/html"},
+			code:   http.StatusNotAcceptable,
+		},
+		{
+			name:     "invalid param",
+			method:   http.MethodGet,
+			path:     "/orders/abc",
+			header:   map[string]string{"X-Trace-Id": "abc"},
+			code:     http.StatusBadRequest,
+			response: "invalid path id: strconv.ParseInt: parsing \"abc\": invalid syntax\n",
+		},
+		{
+			name:     "invalid query",
+			method:   http.MethodGet,
+			path:     "/orders/42?page=x",
+			header:   map[string]string{"X-Trace-Id": "abc"},
+			code:     http.StatusBadRequest,
+			response: "invalid query page: strconv.ParseInt: parsing \"x\": invalid syntax\n",
+		},
+		{
+			name:     "missing header",
+			method:   http.MethodGet,
+			path:     "/orders/42",
+			code:     http.StatusBadRequest,
+			response: "invalid header X-Trace-Id: missing value\n",
+		},
+		{
+			name:   "invalid body",
+			method: http.MethodPost,
+			path:   "/orders/42",
+			header: map[string]string{"X-Trace-Id": "abc", "Content-Type": "application/json"},
+			body:   `{"name":`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "too large body",
+			method: http.MethodPost,
+			path:   "/orders/42",
+			header: map[string]string{"X-Trace-Id": "abc", "Content-Type": "application/json"},
+			body:   `{"name":"` + strings.Repeat("a", maxJSONBodySize) + `"}`,
+			code:   http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "unsupported body",
+			method: http.MethodPost,
+			path:   "/orders/42",
+			header: map[string]string{"X-Trace-Id": "abc", "Content-Type": "text/csv"},
+			body:   "a,b",
+			code:   http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "validation",
+			method:   http.MethodGet,
+			path:     "/orders/-1",
+			header:   map[string]string{"X-Trace-Id": "abc"},
+			code:     http.StatusBadRequest,
+			response: "id must be positive\n",
+		},
+		{
+			name:     "handler error",
+			method:   http.MethodGet,
+			path:     "/fail",
+			code:     http.StatusConflict,
+			response: "conflict\n",
+		},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tx.method, tx.path, strings.NewReader(tx.body))
+			for k, v := range tx.header {
+				req.Header.Set(k, v)
+			}
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d (%s)", tx.code, rw.Code, rw.Body.String()))
+			if tx.contentType != "" {
+				assert(t, rw.Header().Get("Content-Type") == tx.contentType, fmt.Sprintf("content type > expected: %s, got: %s", tx.contentType, rw.Header().Get("Content-Type")))
+			}
+			if tx.response != "" {
+				assert(t, rw.Body.String() == tx.response, fmt.Sprintf("body > expected: %s, got: %s", tx.response, rw.Body.String()))
+			}
+		})
+	}
+}
+
+func TestTyped_WithoutErrorHandler(t *testing.T) {
+	r := New()
+	r.POST("/orders/:id", Typed(func(ctx context.Context, in typedTestInput) (typedTestOutput, error) {
+		return typedTestOutput{}, nil
+	}))
+	srv := r.Serve()
+
+	tt := []struct {
+		name   string
+		header map[string]string
+		path   string
+		body   string
+		code   int
+	}{
+		{name: "bind", path: "/orders/abc", header: map[string]string{"X-Trace-Id": "abc"}, code: http.StatusBadRequest},
+		{name: "validation", path: "/orders/-1", header: map[string]string{"X-Trace-Id": "abc"}, code: http.StatusBadRequest},
+		{name: "not acceptable", path: "/orders/42", header: map[string]string{"X-Trace-Id": "abc", "Accept": "text/html"}, code: http.StatusNotAcceptable},
+		{name: "unsupported body", path: "/orders/42", header: map[string]string{"X-Trace-Id": "abc", "Content-Type": "text/csv"}, body: "a,b", code: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tx.path, strings.NewReader(tx.body))
+			for k, v := range tx.header {
+				req.Header.Set(k, v)
+			}
+			srv.ServeHTTP(rw, req)
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d (%s)", tx.code, rw.Code, rw.Body.String()))
+		})
+	}
+}
+
+type typedTestCreated struct {
+	ID int `json:"id" xml:"id"`
+}
+
+func (typedTestCreated) StatusCode() int {
+	return http.StatusCreated
+}
+
+type typedTestDeleted struct{}
+
+func (typedTestDeleted) StatusCode() int {
+	return http.StatusNoContent
+}
+
+func TestTyped_StatusCode(t *testing.T) {
+	r := New()
+	r.POST("/orders", Typed(func(ctx context.Context, in struct{}) (typedTestCreated, error) {
+		return typedTestCreated{ID: 42}, nil
+	}))
+	r.DELETE("/orders/:id", Typed(func(ctx context.Context, in struct{}) (typedTestDeleted, error) {
+		return typedTestDeleted{}, nil
+	}))
+	r.GET("/orders/:id", Typed(func(ctx context.Context, in struct{}) (*typedTestCreated, error) {
+		return nil, nil
+	}))
+	srv := r.Serve()
+
+	tt := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{method: http.MethodPost, path: "/orders", code: http.StatusCreated, body: `{"id":42}`},
+		{method: http.MethodDelete, path: "/orders/42", code: http.StatusNoContent, body: ""},
+		{method: http.MethodGet, path: "/orders/42", code: http.StatusOK, body: "null"},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.method, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			srv.ServeHTTP(rw, httptest.NewRequest(tx.method, tx.path, nil))
+
+			assert(t, rw.Code == tx.code, fmt.Sprintf("http status > expected: %d, got: %d", tx.code, rw.Code))
+			assert(t, rw.Body.String() == tx.body, fmt.Sprintf("body > expected: %s, got: %s", tx.body, rw.Body.String()))
+		})
+	}
+}
+
+func TestTyped_NotAcceptable_SkipsHandler(t *testing.T) {
+	executed := false
+	r := New()
+	r.POST("/orders", Typed(func(ctx context.Context, in struct{}) (typedTestCreated, error) {
+		executed = true
+		return typedTestCreated{}, nil
+	}))
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set("Accept", "text/html")
+	r.Serve().ServeHTTP(rw, req)
+
+	assert(t, rw.Code == http.StatusNotAcceptable, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusNotAcceptable, rw.Code))
+	assert(t, !executed, "handler > expected not to be executed")
+}
+
+func TestTyped_WithoutBody(t *testing.T) {
+	r := New()
+	r.GET("/orders/:id", Typed(func(ctx context.Context, in struct {
+		ID   int `path:"id"`
+		Page int `query:"page"`
+	}) (typedTestOutput, error) {
+		return typedTestOutput{Summary: fmt.Sprintf("id=%d page=%d", in.ID, in.Page)}, nil
+	}))
+
+	// The Content-Type isn't checked, since the input doesn't bind a body.
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders/42?page=2", strings.NewReader("a,b"))
+	req.Header.Set("Content-Type", "text/csv")
+	r.Serve().ServeHTTP(rw, req)
+
+	expected := `{"summary":"id=42 page=2"}`
+	assert(t, rw.Code == http.StatusOK, fmt.Sprintf("http status > expected: %d, got: %d", http.StatusOK, rw.Code))
+	assert(t, rw.Body.String() == expected, fmt.Sprintf("body > expected: %s, got: %s", expected, rw.Body.String()))
+}
+
+func TestTyped_BindError(t *testing.T) {
+	h := Typed(func(ctx context.Context, in struct {
+		Page int `query:"page"`
+	}) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?page=x", nil)
+	err := h(httptest.NewRecorder(), req, Route{})
+
+	var httpErr *HTTPError
+	assert(t, errors.As(err, &httpErr) && httpErr.Code == http.StatusBadRequest, fmt.Sprintf("error > expected: HTTP 400, got: %v", err))
+
+	var bindErr *BindError
+	assert(t, errors.As(err, &bindErr), fmt.Sprintf("error > expected: BindError, got: %v", err))
+	assert(t, bindErr.Source == "query" && bindErr.Field == "page", fmt.Sprintf("bind error > expected: query page, got: %s %s", bindErr.Source, bindErr.Field))
+}
+
+func TestTyped_InvalidInput(t *testing.T) {
+	tt := []struct {
+		name string
+		f    func()
+	}{
+		{name: "non-struct", f: func() {
+			Typed(func(ctx context.Context, in int) (int, error) { return 0, nil })
+		}},
+		{name: "unsupported field", f: func() {
+			Typed(func(ctx context.Context, in struct {
+				M map[string]string `query:"m"`
+			}) (int, error) {
+				return 0, nil
+			})
+		}},
+		{name: "nil", f: func() {
+			Typed[struct{}, int](nil)
+		}},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.name, func(t *testing.T) {
+			defer func() {
+				assert(t, recover() != nil, "expected a panic")
+			}()
+
+			tx.f()
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tt := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: "application/json"},
+		{accept: "*/*", expected: "application/json"},
+		{accept: "application/*", expected: "application/json"},
+		{accept: "text/*", expected: "text/xml"},
+		{accept: "application/xml", expected: "application/xml"},
+		{accept: "application/json;q=0.1, */*;q=0.5", expected: "application/xml"},
+		{accept: "application/json;q=0", expected: ""},
+		{accept: "text/html", expected: ""},
+	}
+
+	for _, tx := range tt {
+		t.Run(tx.accept, func(t *testing.T) {
+			got := negotiate(tx.accept, offers)
+			assert(t, got == tx.expected, fmt.Sprintf("media type > expected: %s, got: %s", tx.expected, got))
+		})
+	}
+}
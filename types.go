@@ -8,17 +8,86 @@ import "net/http"
 // When passing Route to a goroutine, make to sure pass a copy (use Copy method)
 // instead of the original Route object. The reason being Route.Params is pooled into a sync.Pool when the
 // request is completed.
+//
+// Global middlewares receive a Route for the requests which didn't match a route as well.
+// In that case, Route.Path is empty and Route.Miss describes the kind of the miss.
 type Route struct {
 	Params Params
 	Path   string
+	Miss   MissKind
+	Meta   *RouteMeta // Metadata and tags of the route. See Core.Meta and Core.Tags.
+	svr    *Server    // Server which dispatched the request. Used to compute the allowed methods of the path.
+
+	allowed *[]string // Allowed methods of the path for the misses. A pointer keeps Route comparable.
+}
+
+// allowedMethods returns the allowed methods of the path the Route of a miss was created with.
+func (route Route) allowedMethods() []string {
+	if route.allowed == nil {
+		return nil
+	}
+	return *route.allowed
+}
+
+// MissKind describes why a request didn't execute a route.
+type MissKind uint8
+
+const (
+	// MissNone denotes the request matched a route.
+	MissNone MissKind = iota
+
+	// MissNotFound denotes a route was not found for the request.
+	MissNotFound
+
+	// MissMethodNotAllowed denotes a route was not found for the request's method, but the path has been registered for other HTTP methods.
+	MissMethodNotAllowed
+
+	// MissAutoOptions denotes the OPTIONS request is replied with the allowed methods. See Router.UseAutoOptions.
+	MissAutoOptions
+
+	// MissTrailingSlashRedirect denotes the request is redirected to the path with/without the trailing slash.
+	MissTrailingSlashRedirect
+
+	// MissPathCorrectionRedirect denotes the request is redirected to the corrected path.
+	MissPathCorrectionRedirect
+
+	// MissNotAcceptable denotes none of the routes registered with matchers for the request's path match the request,
+	// and the request is replied with HTTP 406 (http.StatusNotAcceptable) status. See Router.UseMatcherMiss.
+	MissNotAcceptable
+)
+
+// String returns the name of the MissKind.
+func (k MissKind) String() string {
+	switch k {
+	case MissNone:
+		return "none"
+	case MissNotFound:
+		return "not found"
+	case MissMethodNotAllowed:
+		return "method not allowed"
+	case MissAutoOptions:
+		return "auto options"
+	case MissTrailingSlashRedirect:
+		return "trailing slash redirect"
+	case MissPathCorrectionRedirect:
+		return "path correction redirect"
+	case MissNotAcceptable:
+		return "not acceptable"
+	default:
+		return ""
+	}
 }
 
 // Copy returns a copy of the [Route].
 // It calls [Params.Copy] implicitly to copy the underlying [Route.Params] object.
 func (r Route) Copy() Route {
 	return Route{
-		Params: r.Params.Copy(),
-		Path:   r.Path,
+		Params:  r.Params.Copy(),
+		Path:    r.Path,
+		Miss:    r.Miss,
+		Meta:    r.Meta,
+		svr:     r.svr,
+		allowed: allowedRef(append([]string(nil), r.allowedMethods()...)),
 	}
 }
 
@@ -0,0 +1,179 @@
+package shift
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrRouteNotFound is returned when building a URL for a route name which is not registered.
+	ErrRouteNotFound = errors.New("route not found")
+
+	// ErrMissingParam is returned when building a URL without a value for a route param.
+	ErrMissingParam = errors.New("missing route param")
+
+	// ErrUnexpectedParam is returned when building a URL with a value for a param which is not defined in the route.
+	ErrUnexpectedParam = errors.New("unexpected route param")
+
+	// ErrInvalidParam is returned when building a URL with a param value which forms a dot segment (. or ..).
+	ErrInvalidParam = errors.New("invalid route param")
+)
+
+// URL builds the URL path of the route registered with the provided name.
+// params are key-value pairs of the route params. e.g.: "id", "42", "rest", "docs/readme.md"
+//
+// Param values are percent-encoded. Wildcard param values are percent-encoded per segment, preserving the slashes.
+// Optional params (e.g.: /archive/:year/:month?) may be omitted along with their segments.
+// It returns an error if the route name is not registered, a route param is missing, a provided param is not
+// defined in the route, or the param values form a dot segment (. or ..) which would traverse the path.
+func (svr *Server) URL(name string, params ...string) (string, error) {
+	path, ok := svr.names[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+
+	return buildURL(path, params)
+}
+
+// URLFor builds the URL path of the route registered with the provided name.
+// See Server.URL for details.
+func (r *Router) URLFor(name string, params ...string) (string, error) {
+	for _, log := range *r.logs {
+		if log.name == name {
+			return buildURL(log.path, params)
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+}
+
+// namedRoutes maps the route names to the route paths.
+func namedRoutes(logs []routeLog) map[string]string {
+	names := map[string]string{}
+	for _, log := range logs {
+		if log.name != "" {
+			names[log.name] = log.path
+		}
+	}
+	return names
+}
+
+// buildURL expands the route path with the provided key-value pairs of params.
+func buildURL(path string, pairs []string) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("params must be key-value pairs, got odd number of values: %d", len(pairs))
+	}
+
+	b := make([]byte, 0, len(path))
+	b = append(b, '/')
+
+	used := 0
+	omitted := "" // Key of the omitted optional param. Following segments are omitted as well.
+	r := newRouteScanner(path[1:])
+	for seg := r.next(); seg != ""; seg = r.next() {
+		switch seg[0] {
+		case ':':
+			key, _ := splitParam(seg)
+			value, ok := lookupPair(pairs, key)
+			if ok {
+				used++
+			}
+
+			if omitted != "" {
+				if ok && value != "" {
+					return "", fmt.Errorf("%w: %s in %s", ErrMissingParam, omitted, path)
+				}
+				continue
+			}
+
+			if !ok || value == "" {
+				if !isOptionalParam(seg) {
+					return "", fmt.Errorf("%w: %s in %s", ErrMissingParam, key, path)
+				}
+
+				// Omit the segment along with the preceding slash.
+				omitted = key
+				if i := bytes.LastIndexByte(b, '/'); i > 0 {
+					b = b[:i]
+				} else {
+					b = b[:1]
+				}
+				continue
+			}
+
+			b = append(b, url.PathEscape(value)...)
+		case '*':
+			key := seg[1:]
+			value, ok := lookupPair(pairs, key)
+			if !ok {
+				return "", fmt.Errorf("%w: %s in %s", ErrMissingParam, key, path)
+			}
+			used++
+
+			for i, s := range strings.Split(value, "/") {
+				if i > 0 {
+					b = append(b, '/')
+				}
+				b = append(b, url.PathEscape(s)...)
+			}
+		default:
+			if omitted == "" {
+				b = append(b, seg...)
+			}
+		}
+	}
+
+	if used != len(pairs)/2 {
+		for i := 0; i < len(pairs); i += 2 {
+			if !hasParam(path, pairs[i]) {
+				return "", fmt.Errorf("%w: %s in %s", ErrUnexpectedParam, pairs[i], path)
+			}
+		}
+
+		return "", fmt.Errorf("params contain duplicate keys for %s", path)
+	}
+
+	// Param values forming dot segments would be resolved relative to the path, traversing it.
+	for _, seg := range bytes.Split(b, []byte{'/'}) {
+		if isDotSegment(string(seg)) {
+			return "", fmt.Errorf("%w: dot segment %s in %s", ErrInvalidParam, seg, b)
+		}
+	}
+
+	return string(b), nil
+}
+
+// isDotSegment reports whether the segment is . or .., which are resolved relative to the path by the clients.
+func isDotSegment(s string) bool {
+	return s == "." || s == ".."
+}
+
+func lookupPair(pairs []string, key string) (string, bool) {
+	for i := 0; i < len(pairs); i += 2 {
+		if pairs[i] == key {
+			return pairs[i+1], true
+		}
+	}
+	return "", false
+}
+
+// hasParam reports whether the route path defines a param or a wildcard with the provided key.
+func hasParam(path string, key string) bool {
+	r := newRouteScanner(path[1:])
+	for seg := r.next(); seg != ""; seg = r.next() {
+		switch seg[0] {
+		case ':':
+			if name, _ := splitParam(seg); name == key {
+				return true
+			}
+		case '*':
+			if seg[1:] == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package shift
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestServer_URL(t *testing.T) {
+	r := newTestRouter()
+	r.Name("home").GET("/", fakeHandler())
+	r.Name("user.posts").GET("/users/:id/posts/*rest", fakeHandler())
+	r.Name("jobs").Map([]string{"GET", "POST"}, "/v:version/jobs", fakeHandler())
+	r.Name("post").GET("/posts/:id<int>", fakeHandler())
+	r.Name("file").GET("/files/:name.:ext", fakeHandler())
+	r.Name("archive").GET("/archive/:year/:month?/:day?", fakeHandler())
+	r.Group("/api", func(g *Group) {
+		g.Name("api.search").GET("/search/:q", fakeHandler())
+	})
+
+	srv := r.Serve()
+
+	tt := []struct {
+		name   string
+		params []string
+		url    string
+		err    error
+	}{
+		{name: "home", url: "/"},
+		{name: "user.posts", params: []string{"id", "42", "rest", "2023/12/hello world.md"}, url: "/users/42/posts/2023/12/hello%20world.md"},
+		{name: "user.posts", params: []string{"rest", "", "id", "a/b"}, url: "/users/a%2Fb/posts/"},
+		{name: "jobs", params: []string{"version", "2"}, url: "/v2/jobs"},
+		{name: "post", params: []string{"id", "7"}, url: "/posts/7"},
+		{name: "api.search", params: []string{"q", "go?lang"}, url: "/api/search/go%3Flang"},
+		{name: "file", params: []string{"name", "report", "ext", "pdf"}, url: "/files/report.pdf"},
+		{name: "archive", params: []string{"year", "2024"}, url: "/archive/2024"},
+		{name: "archive", params: []string{"year", "2024", "month", "02"}, url: "/archive/2024/02"},
+		{name: "archive", params: []string{"year", "2024", "month", "02", "day", "29"}, url: "/archive/2024/02/29"},
+		{name: "archive", params: []string{"year", "2024", "month", ""}, url: "/archive/2024"},
+		{name: "unknown", err: ErrRouteNotFound},
+		{name: "user.posts", params: []string{"id", "42"}, err: ErrMissingParam},
+		{name: "jobs", params: []string{"version", ""}, err: ErrMissingParam},
+		{name: "file", params: []string{"name", "report"}, err: ErrMissingParam},
+		{name: "archive", params: []string{"month", "02"}, err: ErrMissingParam},
+		{name: "archive", params: []string{"year", "2024", "day", "29"}, err: ErrMissingParam},
+		{name: "archive", params: []string{"year", "2024", "week", "3"}, err: ErrUnexpectedParam},
+		{name: "jobs", params: []string{"version", "2", "foo", "bar"}, err: ErrUnexpectedParam},
+		{name: "user.posts", params: []string{"id", "42", "rest", "../../admin"}, err: ErrInvalidParam},
+		{name: "user.posts", params: []string{"id", "42", "rest", "docs/./readme.md"}, err: ErrInvalidParam},
+		{name: "user.posts", params: []string{"id", "..", "rest", "readme.md"}, err: ErrInvalidParam},
+		{name: "jobs", params: []string{"version", "."}, url: "/v./jobs"},
+		{name: "file", params: []string{"name", ".", "ext", "."}, url: "/files/..."},
+	}
+
+	for _, tx := range tt {
+		t.Run(fmt.Sprintf("%s%v", tx.name, tx.params), func(t *testing.T) {
+			for _, build := range []func(string, ...string) (string, error){srv.URL, r.URLFor} {
+				url, err := build(tx.name, tx.params...)
+				if tx.err != nil {
+					assert(t, errors.Is(err, tx.err), fmt.Sprintf("error > expected: %v, got: %v", tx.err, err))
+					continue
+				}
+
+				assert(t, err == nil, fmt.Sprintf("error > expected: <nil>, got: %v", err))
+				assert(t, url == tx.url, fmt.Sprintf("url > expected: %s, got: %s", tx.url, url))
+			}
+		})
+	}
+
+	t.Run("optional root segment", func(t *testing.T) {
+		r := newTestRouter()
+		r.Name("home").GET("/:lang?", fakeHandler())
+
+		url, err := r.URLFor("home")
+		assert(t, err == nil && url == "/", fmt.Sprintf("url > expected: /, got: %s (%v)", url, err))
+
+		url, err = r.URLFor("home", "lang", "en")
+		assert(t, err == nil && url == "/en", fmt.Sprintf("url > expected: /en, got: %s (%v)", url, err))
+	})
+
+	t.Run("odd params", func(t *testing.T) {
+		_, err := srv.URL("jobs", "version")
+		assert(t, err != nil, "expected an error")
+	})
+
+	t.Run("duplicate params", func(t *testing.T) {
+		_, err := srv.URL("jobs", "version", "1", "version", "2")
+		assert(t, err != nil, "expected an error")
+	})
+}
+
+func TestCore_Name(t *testing.T) {
+	t.Run("routes", func(t *testing.T) {
+		r := newTestRouter()
+		r.Name("user").Map([]string{"GET", "DELETE"}, "/users/:id", fakeHandler())
+		r.GET("/users", fakeHandler())
+
+		for _, route := range r.Routes() {
+			expected := ""
+			if route.Path == "/users/:id" {
+				expected = "user"
+			}
+			assert(t, route.Name == expected, fmt.Sprintf("%s %s name > expected: %s, got: %s", route.Method, route.Path, expected, route.Name))
+		}
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		r := newTestRouter()
+		r.Name("user").GET("/users/:id", fakeHandler())
+		rec := panicHandler(func() {
+			r.Name("user").GET("/members/:id", fakeHandler())
+		})
+		assert(t, rec != nil, "expected to panic")
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		r := newTestRouter()
+		rec := panicHandler(func() {
+			r.Name("")
+		})
+		assert(t, rec != nil, "expected to panic")
+	})
+}